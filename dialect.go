@@ -0,0 +1,172 @@
+package gqbd
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Dialect renders the database-specific parts of a query: identifier quoting,
+// bind placeholders, pagination and RETURNING support. The builders compose the
+// rest of the statement, so a new database only needs to implement these.
+type Dialect interface {
+	// EscapeIdentifier quotes a single, already validated identifier segment
+	// such as a table or column name. Qualified names are split before calling it.
+	EscapeIdentifier(name string) (string, error)
+
+	// Placeholder returns the bind parameter for the 1-based argument position index.
+	Placeholder(index int) string
+
+	// LimitOffset renders the pagination clause, including its leading space, and
+	// returns the args it binds. limit and offset are -1 when unset, nextIndex is
+	// the position of the first bound arg, and ordered reports whether the query
+	// has an ORDER BY clause.
+	LimitOffset(limit, offset, nextIndex int, ordered bool) (string, []interface{}, error)
+
+	// SupportsReturning reports whether INSERT ... RETURNING is available.
+	SupportsReturning() bool
+}
+
+// builtinDialects are the dialects shipped with the package, keyed by DBType.
+var builtinDialects = map[DBType]Dialect{
+	PostgreSQL: postgresDialect{},
+	Redshift:   redshiftDialect{},
+	MariaDB:    mysqlDialect{},
+	Mysql:      mysqlDialect{},
+	SQLite:     sqliteDialect{},
+	SQLServer:  sqlServerDialect{},
+}
+
+var (
+	dialectsMu sync.RWMutex
+	dialects   = map[DBType]Dialect{}
+)
+
+/*
+RegisterDialect
+
+@ dbType: Database type to register the dialect under (e.g. "cockroachdb")
+@ d: Dialect implementation used by builders created for dbType
+@ Return: Error if d is nil or dbType is built in or already registered
+*/
+func RegisterDialect(dbType DBType, d Dialect) error {
+	if d == nil {
+		return fmt.Errorf("dialect for %s is nil", dbType)
+	}
+	if _, ok := builtinDialects[dbType]; ok {
+		return fmt.Errorf("%s is a built-in dialect; use ReplaceDialect to override it", dbType)
+	}
+	dialectsMu.Lock()
+	defer dialectsMu.Unlock()
+	if _, ok := dialects[dbType]; ok {
+		return fmt.Errorf("dialect for %s is already registered; use ReplaceDialect to override it", dbType)
+	}
+	dialects[dbType] = d
+	return nil
+}
+
+/*
+ReplaceDialect
+
+@ dbType: Database type whose dialect is set, including built-in types such as PostgreSQL
+@ d: Dialect implementation used by builders created for dbType from now on
+@ Return: Error if d is nil
+*/
+func ReplaceDialect(dbType DBType, d Dialect) error {
+	if d == nil {
+		return fmt.Errorf("dialect for %s is nil", dbType)
+	}
+	dialectsMu.Lock()
+	defer dialectsMu.Unlock()
+	dialects[dbType] = d
+	return nil
+}
+
+// dialectFor returns the dialect registered for dbType, then the built-in one,
+// falling back to MySQL for unknown types as the builders always have.
+func dialectFor(dbType DBType) Dialect {
+	dialectsMu.RLock()
+	d, ok := dialects[dbType]
+	dialectsMu.RUnlock()
+	if ok {
+		return d
+	}
+	if d, ok := builtinDialects[dbType]; ok {
+		return d
+	}
+	return mysqlDialect{}
+}
+
+// numberedPlaceholders reports whether the dialect binds $N or @pN instead of "?".
+func numberedPlaceholders(d Dialect) bool {
+	return d.Placeholder(1) != d.Placeholder(2)
+}
+
+// placeholderPrefix returns the marker written before N in numbered placeholders.
+func placeholderPrefix(d Dialect) string {
+	return strings.TrimSuffix(d.Placeholder(1), "1")
+}
+
+// Placeholder styles accepted by WithPlaceholderStyle and reported by PlaceholderStyle.
+const (
+	PlaceholderDollar   = "dollar"   // $1, $2 (PostgreSQL, pgx)
+	PlaceholderQuestion = "question" // ?, ? (MySQL, SQLite)
+	PlaceholderAt       = "at"       // @p1, @p2 (SQL Server)
+	PlaceholderColon    = "colon"    // :1, :2 (Oracle-style drivers)
+)
+
+// styledDialect overrides the bind placeholders of the dialect it wraps.
+type styledDialect struct {
+	Dialect
+	style string
+}
+
+func (d styledDialect) Placeholder(index int) string {
+	switch d.style {
+	case PlaceholderDollar:
+		return fmt.Sprintf("$%d", index)
+	case PlaceholderAt:
+		return fmt.Sprintf("@p%d", index)
+	case PlaceholderColon:
+		return fmt.Sprintf(":%d", index)
+	default:
+		return "?"
+	}
+}
+
+// LimitOffset renders the wrapped dialect's clause and swaps in the forced style,
+// since the wrapped dialect writes its own placeholders.
+func (d styledDialect) LimitOffset(limit, offset, nextIndex int, ordered bool) (string, []interface{}, error) {
+	clause, args, err := d.Dialect.LimitOffset(limit, offset, nextIndex, ordered)
+	if err != nil {
+		return "", nil, err
+	}
+	if numberedPlaceholders(d.Dialect) {
+		// Highest index first so $1 is never replaced inside $10
+		for i := len(args) - 1; i >= 0; i-- {
+			clause = strings.Replace(clause, d.Dialect.Placeholder(nextIndex+i), d.Placeholder(nextIndex+i), 1)
+		}
+		return clause, args, nil
+	}
+	for i := range args {
+		clause = strings.Replace(clause, "?", d.Placeholder(nextIndex+i), 1)
+	}
+	return clause, args, nil
+}
+
+// placeholderStyle names the placeholder style d renders, or "" for styles
+// outside the PlaceholderDollar family of constants.
+func placeholderStyle(d Dialect) string {
+	switch d.Placeholder(1) {
+	case "?":
+		return PlaceholderQuestion
+	case "$1":
+		return PlaceholderDollar
+	case "@p1":
+		return PlaceholderAt
+	case ":1":
+		return PlaceholderColon
+	default:
+		return ""
+	}
+}
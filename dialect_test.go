@@ -0,0 +1,206 @@
+package gqbd_test
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/donghquinn/gqbd"
+)
+
+// fakeDialect quotes with angle brackets, binds :N and paginates with FIRST/SKIP.
+type fakeDialect struct{}
+
+func (fakeDialect) EscapeIdentifier(name string) (string, error) {
+	return "<" + name + ">", nil
+}
+
+func (fakeDialect) Placeholder(index int) string {
+	return fmt.Sprintf(":%d", index)
+}
+
+func (d fakeDialect) LimitOffset(limit, offset, nextIndex int, ordered bool) (string, []interface{}, error) {
+	clause := ""
+	var args []interface{}
+	if limit >= 0 {
+		clause += " FIRST " + d.Placeholder(nextIndex+len(args))
+		args = append(args, limit)
+	}
+	if offset >= 0 {
+		clause += " SKIP " + d.Placeholder(nextIndex+len(args))
+		args = append(args, offset)
+	}
+	return clause, args, nil
+}
+
+func (fakeDialect) SupportsReturning() bool {
+	return false
+}
+
+const fakeDB gqbd.DBType = "fakedb"
+
+/*
+BuildSelect through a registered dialect
+
+@ Return: SELECT rendered with the custom dialect's quoting, placeholders and pagination
+*/
+func TestBuildSelectCustomDialect(t *testing.T) {
+	if err := gqbd.ReplaceDialect(fakeDB, fakeDialect{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	query, args, err := gqbd.BuildSelect(fakeDB, "users", "id", "name").
+		Where("age > ?", 18).
+		WhereIn("status", []interface{}{"active", "pending"}).
+		OrderBy("id", "ASC", nil).
+		Limit(10).
+		Offset(20).
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := "SELECT <id>, <name> FROM <users> WHERE age > :1 AND <status> IN (:2, :3) ORDER BY <id> ASC FIRST :4 SKIP :5"
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{18, "active", "pending", 10, 20}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("expected args %v, got %v", expectedArgs, args)
+	}
+}
+
+/*
+BuildUpdate through a registered dialect
+
+@ Return: UPDATE with SET bound before the renumbered WHERE args
+*/
+func TestBuildUpdateCustomDialect(t *testing.T) {
+	if err := gqbd.ReplaceDialect(fakeDB, fakeDialect{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	query, args, err := gqbd.BuildUpdate(fakeDB, "users").
+		Set(map[string]interface{}{"name": "Bob", "age": 31}).
+		Where("id = ?", 7).
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := "UPDATE <users> SET <age> = :1, <name> = :2 WHERE id = :3"
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{31, "Bob", 7}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("expected args %v, got %v", expectedArgs, args)
+	}
+
+	if _, _, err := gqbd.BuildInsert(fakeDB, "users").
+		Values(map[string]interface{}{"name": "Bob"}).
+		Returning("id").
+		Build(); err == nil {
+		t.Error("expected error for Returning() on a dialect without RETURNING")
+	}
+}
+
+// cockroachDialect is a Postgres-compatible dialect that renders identifiers in
+// upper case so tests can tell it apart from the built-in PostgreSQL dialect.
+type cockroachDialect struct{}
+
+func (cockroachDialect) EscapeIdentifier(name string) (string, error) {
+	return `"` + strings.ToUpper(name) + `"`, nil
+}
+
+func (cockroachDialect) Placeholder(index int) string {
+	return fmt.Sprintf("$%d", index)
+}
+
+func (d cockroachDialect) LimitOffset(limit, offset, nextIndex int, ordered bool) (string, []interface{}, error) {
+	if limit < 0 {
+		return "", nil, nil
+	}
+	return " LIMIT " + d.Placeholder(nextIndex), []interface{}{limit}, nil
+}
+
+func (cockroachDialect) SupportsReturning() bool {
+	return true
+}
+
+// registeredDialects numbers the types TestRegisterDialect registers, since a
+// type can only be registered once per process and tests may run repeatedly.
+var registeredDialects int
+
+/*
+RegisterDialect
+
+@ Return: SELECT built with a registered dialect, and errors for nil dialects, built-in and already registered types
+*/
+func TestRegisterDialect(t *testing.T) {
+	registeredDialects++
+	cockroach := gqbd.DBType(fmt.Sprintf("cockroachdb%d", registeredDialects))
+	if err := gqbd.RegisterDialect(cockroach, cockroachDialect{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	query, args, err := gqbd.BuildSelect(cockroach, "public.users", "id").
+		Where("id = ?", 1).
+		Limit(5).
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := `SELECT "ID" FROM "PUBLIC"."USERS" WHERE id = $1 LIMIT $2`
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{1, 5}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("expected args %v, got %v", expectedArgs, args)
+	}
+
+	if err := gqbd.RegisterDialect(cockroach, fakeDialect{}); err == nil {
+		t.Error("expected error when registering an already registered type")
+	}
+	if err := gqbd.RegisterDialect(gqbd.PostgreSQL, cockroachDialect{}); err == nil {
+		t.Error("expected error when registering over a built-in dialect")
+	}
+	if err := gqbd.RegisterDialect(gqbd.DBType("unregistered"), nil); err == nil {
+		t.Error("expected error for a nil dialect")
+	}
+
+	// The built-in dialect must be untouched by the rejected registration
+	query, _, err = gqbd.BuildSelect(gqbd.PostgreSQL, "users", "id").Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if query != `SELECT "id" FROM "users"` {
+		t.Errorf("unexpected PostgreSQL query: %s", query)
+	}
+}
+
+/*
+ReplaceDialect
+
+@ Return: Builders using the replacement dialect, and an error for a nil dialect
+*/
+func TestReplaceDialect(t *testing.T) {
+	const replaced gqbd.DBType = "replaceddb"
+	if err := gqbd.ReplaceDialect(replaced, fakeDialect{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := gqbd.ReplaceDialect(replaced, cockroachDialect{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	query, _, err := gqbd.BuildSelect(replaced, "users", "id").Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := `SELECT "ID" FROM "USERS"`; query != expected {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expected, query)
+	}
+
+	if err := gqbd.ReplaceDialect(replaced, nil); err == nil {
+		t.Error("expected error for a nil dialect")
+	}
+}
@@ -0,0 +1,40 @@
+package gqbd
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Sentinel errors for the categories callers usually need to tell apart.
+// Builder errors keep their descriptive messages and match these with errors.Is.
+var (
+	// ErrInvalidIdentifier reports an empty, malformed or over-long table, column or alias name.
+	ErrInvalidIdentifier = errors.New("invalid identifier")
+	// ErrWrongOperation reports a method used with a builder of the wrong operation,
+	// such as Values on an UPDATE builder.
+	ErrWrongOperation = errors.New("wrong operation")
+	// ErrNoData reports an INSERT or UPDATE built without any values.
+	ErrNoData = errors.New("no data provided")
+	// ErrPlaceholderMismatch reports a subquery that binds with a different
+	// placeholder style than the query embedding it.
+	ErrPlaceholderMismatch = errors.New("placeholder style mismatch")
+)
+
+// builderError carries a descriptive message while unwrapping to its category.
+type builderError struct {
+	kind error
+	msg  string
+}
+
+func (e *builderError) Error() string {
+	return e.msg
+}
+
+func (e *builderError) Unwrap() error {
+	return e.kind
+}
+
+// newError formats a message that matches kind under errors.Is.
+func newError(kind error, format string, args ...interface{}) error {
+	return &builderError{kind: kind, msg: fmt.Sprintf(format, args...)}
+}
@@ -1,8 +1,16 @@
 package gqbd
 
 import (
+	"database/sql"
+	"database/sql/driver"
 	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // DBType represents the type of database.
@@ -13,8 +21,36 @@ const (
 	MariaDB    DBType = "mariadb"
 	Mysql      DBType = "mysql"
 	SQLite     DBType = "sqlite"
+	Redshift   DBType = "redshift" // PostgreSQL escaping and placeholders, no RETURNING
+	SQLServer  DBType = "sqlserver"
 )
 
+// OrderColumn is a single column and direction of a multi-column ORDER BY.
+type OrderColumn struct {
+	Column    string
+	Direction string
+}
+
+// Filter is a reusable set of clauses, such as tenant scoping, applied with
+// ApplyFilters. Apply adds its clauses to qb and returns it.
+type Filter interface {
+	Apply(qb *QueryBuilder) *QueryBuilder
+}
+
+// FilterFunc adapts a plain function to the Filter interface.
+type FilterFunc func(qb *QueryBuilder) *QueryBuilder
+
+// Apply calls f(qb).
+func (f FilterFunc) Apply(qb *QueryBuilder) *QueryBuilder {
+	return f(qb)
+}
+
+// Statement is a built SQL query together with its bound arguments.
+type Statement struct {
+	Query string
+	Args  []interface{}
+}
+
 // DBConfig holds database connection configuration
 type DBConfig struct {
 	Host     string
@@ -32,6 +68,7 @@ type DBConfig struct {
 type QueryBuilder struct {
 	op         string
 	dbType     DBType
+	dialect    Dialect
 	table      string
 	columns    []string
 	joins      []string
@@ -41,11 +78,44 @@ type QueryBuilder struct {
 	orderBy    string
 	limit      int
 	offset     int
+	limitSet   bool
+	offsetSet  bool
 	args       []interface{}
 	distinct   bool
 	err        error
 	data       map[string]interface{}
 	returning  string
+	valuesKey  string
+	valuesRows []map[string]interface{}
+
+	pushDownLimit bool
+	lockWait      time.Duration
+	notExistsKeys []string
+	tableName     string
+	schema        string
+	insertSet     bool
+	joinArgs      []interface{}
+	rollup        bool
+
+	restartIdentity bool
+	tableAlias      string
+	defaultValues   bool
+	strictGroupBy   bool
+	columnArgs      []interface{}
+	explain         string
+	starColumns     bool
+	caseSets        []string
+	caseArgs        []interface{}
+	havingArgs      []interface{}
+	keepTimeZone    bool
+	suffixes        []string
+	suffixArgs      []interface{}
+	prefixes        []string
+	prefixArgs      []interface{}
+	insertIgnore    bool
+	replace         bool
+	lockOf          []string
+	terminator      bool
 }
 
 
@@ -71,6 +141,24 @@ func BuildInsert(dbType DBType, table string) *QueryBuilder {
 	return qb
 }
 
+// BuildReplace creates a MySQL/MariaDB REPLACE INTO builder. It takes Values like
+// BuildInsert; other database types get an error since they have no REPLACE.
+//
+// Example:
+//   qb := gqbd.BuildReplace(gqbd.MariaDB, "users")
+func BuildReplace(dbType DBType, table string) *QueryBuilder {
+	qb := BuildInsert(dbType, table)
+	if qb.err != nil {
+		return qb
+	}
+	if dbType != MariaDB && dbType != Mysql {
+		qb.err = fmt.Errorf("REPLACE INTO is not supported for %s", dbType)
+		return qb
+	}
+	qb.replace = true
+	return qb
+}
+
 // BuildUpdate creates a new UPDATE query builder for the specified database type.
 // Zero allocations, SQL injection safe.
 //
@@ -93,156 +181,306 @@ func BuildDelete(dbType DBType, table string) *QueryBuilder {
 	return qb
 }
 
+// BuildTruncate creates a new TRUNCATE TABLE query builder for the specified database type.
+// TRUNCATE takes no parameters, so Build returns no args.
+//
+// Example:
+//   qb := gqbd.BuildTruncate(gqbd.PostgreSQL, "users").RestartIdentity()
+func BuildTruncate(dbType DBType, table string) *QueryBuilder {
+	qb := NewQueryBuilder(dbType, table)
+	qb.op = "TRUNCATE"
+	return qb
+}
+
 // NewQueryBuilder creates a new QueryBuilder instance with optimized defaults.
 // Internal function used by Build* methods.
 func NewQueryBuilder(dbType DBType, table string, columns ...string) *QueryBuilder {
-	qb := &QueryBuilder{dbType: dbType}
+	qb := &QueryBuilder{}
+	return qb.Reset(dbType, table, columns...)
+}
+
+// Reset clears the builder for reuse against the given table and columns.
+// Slices are truncated in place so their capacity is kept, which lets hot
+// paths reuse builders without allocating. The operation is preserved.
+func (qb *QueryBuilder) Reset(dbType DBType, table string, columns ...string) *QueryBuilder {
+	qb.clear()
+	qb.dbType = dbType
+	qb.dialect = dialectFor(dbType)
+	qb.tableName = table
 	safeTable, err := EscapeIdentifier(dbType, table)
 	if err != nil {
 		qb.err = err
 		return qb
 	}
 	qb.table = safeTable
-	safeColumns := make([]string, len(columns))
-	for i, col := range columns {
+	for _, col := range columns {
 		safeCol, err := EscapeIdentifier(dbType, col)
 		if err != nil {
 			qb.err = err
 			return qb
 		}
-		safeColumns[i] = safeCol
+		qb.columns = append(qb.columns, safeCol)
 	}
-	if len(safeColumns) == 0 {
-		safeColumns = []string{"*"}
+	if len(qb.columns) == 0 {
+		qb.columns = append(qb.columns, "*")
+		qb.starColumns = true
 	}
-	qb.columns = safeColumns
 	return qb
 }
 
-// Distinct adds DISTINCT clause to SELECT queries.
-// Performance optimized with zero allocations.
-func (qb *QueryBuilder) Distinct() *QueryBuilder {
-	if qb.err != nil {
-		return qb
+// clear zeroes every field except op while keeping slice capacity.
+func (qb *QueryBuilder) clear() {
+	// Drop references to caller values so pooled builders don't pin them
+	for i := range qb.args {
+		qb.args[i] = nil
+	}
+	for i := range qb.joinArgs {
+		qb.joinArgs[i] = nil
+	}
+	for i := range qb.columnArgs {
+		qb.columnArgs[i] = nil
+	}
+	for i := range qb.caseArgs {
+		qb.caseArgs[i] = nil
+	}
+	for i := range qb.havingArgs {
+		qb.havingArgs[i] = nil
+	}
+	for i := range qb.suffixArgs {
+		qb.suffixArgs[i] = nil
+	}
+	for i := range qb.prefixArgs {
+		qb.prefixArgs[i] = nil
+	}
+	*qb = QueryBuilder{
+		op:         qb.op,
+		columns:    qb.columns[:0],
+		joins:      qb.joins[:0],
+		conditions: qb.conditions[:0],
+		groupBy:    qb.groupBy[:0],
+		having:     qb.having[:0],
+		args:       qb.args[:0],
+		joinArgs:   qb.joinArgs[:0],
+		columnArgs: qb.columnArgs[:0],
+		caseSets:   qb.caseSets[:0],
+		caseArgs:   qb.caseArgs[:0],
+		havingArgs: qb.havingArgs[:0],
+		suffixes:   qb.suffixes[:0],
+		suffixArgs: qb.suffixArgs[:0],
+		prefixes:   qb.prefixes[:0],
+		prefixArgs: qb.prefixArgs[:0],
+		lockOf:     qb.lockOf[:0],
 	}
-	qb.distinct = true
-	return qb
 }
 
-// Aggregate adds an aggregate function to SELECT queries (COUNT, SUM, AVG, etc.).
-// SQL injection safe with automatic identifier escaping.
-func (qb *QueryBuilder) Aggregate(function, column string) *QueryBuilder {
-	if qb.err != nil {
-		return qb
+var builderPool = sync.Pool{
+	New: func() interface{} {
+		return &QueryBuilder{}
+	},
+}
+
+// Acquire returns a pooled SELECT builder reset for the given table and columns.
+// Pair every Acquire with Release once the built query is no longer needed.
+//
+// Example:
+//   qb := gqbd.Acquire(gqbd.PostgreSQL, "users", "id", "name")
+//   defer gqbd.Release(qb)
+func Acquire(dbType DBType, table string, columns ...string) *QueryBuilder {
+	qb := builderPool.Get().(*QueryBuilder)
+	qb.op = "SELECT"
+	return qb.Reset(dbType, table, columns...)
+}
+
+// Release returns a builder obtained from Acquire to the pool.
+// The builder and any args slice returned by its Build must not be used afterwards.
+func Release(qb *QueryBuilder) {
+	if qb == nil {
+		return
 	}
-	safeCol, err := EscapeIdentifier(qb.dbType, column)
-	if err != nil {
-		qb.err = err
-		return qb
+	qb.op = ""
+	qb.clear()
+	builderPool.Put(qb)
+}
+
+// Clone returns a deep copy of the builder so a shared base query can be
+// forked into variations (e.g. a count query and a data query) safely.
+func (qb *QueryBuilder) Clone() *QueryBuilder {
+	clone := *qb
+	clone.columns = append([]string(nil), qb.columns...)
+	clone.joins = append([]string(nil), qb.joins...)
+	clone.joinArgs = append([]interface{}(nil), qb.joinArgs...)
+	clone.columnArgs = append([]interface{}(nil), qb.columnArgs...)
+	clone.conditions = append([]string(nil), qb.conditions...)
+	clone.groupBy = append([]string(nil), qb.groupBy...)
+	clone.having = append([]string(nil), qb.having...)
+	clone.args = append([]interface{}(nil), qb.args...)
+	clone.caseSets = append([]string(nil), qb.caseSets...)
+	clone.caseArgs = append([]interface{}(nil), qb.caseArgs...)
+	clone.havingArgs = append([]interface{}(nil), qb.havingArgs...)
+	clone.suffixes = append([]string(nil), qb.suffixes...)
+	clone.suffixArgs = append([]interface{}(nil), qb.suffixArgs...)
+	clone.lockOf = append([]string(nil), qb.lockOf...)
+	clone.prefixes = append([]string(nil), qb.prefixes...)
+	clone.prefixArgs = append([]interface{}(nil), qb.prefixArgs...)
+	clone.notExistsKeys = append([]string(nil), qb.notExistsKeys...)
+	clone.data = copyRow(qb.data)
+	if qb.valuesRows != nil {
+		clone.valuesRows = make([]map[string]interface{}, len(qb.valuesRows))
+		for i, row := range qb.valuesRows {
+			clone.valuesRows[i] = copyRow(row)
+		}
 	}
-	qb.columns = append(qb.columns, fmt.Sprintf("%s(%s)", function, safeCol))
-	return qb
+	return &clone
 }
 
-// LeftJoin adds a LEFT JOIN clause to the query.
-// Table names are automatically escaped for security.
-func (qb *QueryBuilder) LeftJoin(joinTable, onCondition string) *QueryBuilder {
-	if qb.err != nil {
-		return qb
+// copyRow returns a shallow copy of row, or nil for a nil row.
+func copyRow(row map[string]interface{}) map[string]interface{} {
+	if row == nil {
+		return nil
 	}
-	safeTable, err := EscapeIdentifier(qb.dbType, joinTable)
-	if err != nil {
-		qb.err = err
-		return qb
+	copied := make(map[string]interface{}, len(row))
+	for key, val := range row {
+		copied[key] = val
 	}
-	qb.joins = append(qb.joins, fmt.Sprintf("LEFT JOIN %s ON %s", safeTable, onCondition))
-	return qb
+	return copied
 }
 
 /*
-InnerJoin
+SetSchema
 
-@ joinTable: Table name to join
-@ onCondition: Join condition
-@ Return: *QueryBuilder with INNER JOIN added
+@ schema: Schema qualifying the main table and tables joined afterwards
+@ Return: *QueryBuilder with unqualified table names prefixed by the escaped schema
 */
-func (qb *QueryBuilder) InnerJoin(joinTable, onCondition string) *QueryBuilder {
+func (qb *QueryBuilder) SetSchema(schema string) *QueryBuilder {
 	if qb.err != nil {
 		return qb
 	}
-	safeTable, err := EscapeIdentifier(qb.dbType, joinTable)
+	if schema == "" || strings.ContainsAny(schema, ". ") {
+		qb.err = newError(ErrInvalidIdentifier, "invalid schema name: %q", schema)
+		return qb
+	}
+	if len(qb.joins) > 0 {
+		qb.err = fmt.Errorf("SetSchema() must be called before adding joins")
+		return qb
+	}
+	qb.schema = schema
+	safeTable, err := qb.escapeTable(qb.tableName)
 	if err != nil {
 		qb.err = err
 		return qb
 	}
-	qb.joins = append(qb.joins, fmt.Sprintf("INNER JOIN %s ON %s", safeTable, onCondition))
+	qb.table = safeTable
+	if qb.tableAlias != "" {
+		qb.table += " AS " + qb.tableAlias
+	}
 	return qb
 }
 
 /*
-RightJoin
+As
 
-@ joinTable: Table name to join
-@ onCondition: Join condition
-@ Return: *QueryBuilder with RIGHT JOIN added
+@ alias: Alias for the primary table, referenced as alias.column in conditions
+@ Return: *QueryBuilder rendering FROM table AS alias with the alias escaped
 */
-func (qb *QueryBuilder) RightJoin(joinTable, onCondition string) *QueryBuilder {
+func (qb *QueryBuilder) As(alias string) *QueryBuilder {
 	if qb.err != nil {
 		return qb
 	}
-	safeTable, err := EscapeIdentifier(qb.dbType, joinTable)
+	if qb.tableAlias != "" || len(strings.Fields(qb.tableName)) > 1 {
+		qb.err = fmt.Errorf("table %s already has an alias", qb.tableName)
+		return qb
+	}
+	if alias == "" || strings.ContainsAny(alias, ". ") {
+		qb.err = newError(ErrInvalidIdentifier, "invalid table alias: %q", alias)
+		return qb
+	}
+	safeAlias, err := escapeIdentifierName(qb.dbType, alias)
 	if err != nil {
 		qb.err = err
 		return qb
 	}
-	qb.joins = append(qb.joins, fmt.Sprintf("RIGHT JOIN %s ON %s", safeTable, onCondition))
+	qb.tableAlias = safeAlias
+	qb.table += " AS " + safeAlias
 	return qb
 }
 
-// Where adds a WHERE condition with parameter binding.
-// Automatically handles database-specific placeholder formats ($N for PostgreSQL, ? for MySQL/MariaDB).
-// SQL injection safe through proper parameter binding.
-func (qb *QueryBuilder) Where(condition string, args ...interface{}) *QueryBuilder {
+// escapeTable escapes a table reference, qualifying it with the builder's schema
+// unless it is already schema-qualified.
+func (qb *QueryBuilder) escapeTable(table string) (string, error) {
+	if qb.schema != "" {
+		if parts := strings.Fields(table); len(parts) > 0 && !strings.Contains(parts[0], ".") {
+			table = qb.schema + "." + strings.TrimSpace(table)
+		}
+	}
+	return EscapeIdentifier(qb.dbType, table)
+}
+
+// Distinct adds DISTINCT clause to SELECT queries.
+// Performance optimized with zero allocations.
+func (qb *QueryBuilder) Distinct() *QueryBuilder {
 	if qb.err != nil {
 		return qb
 	}
-	updatedCondition := ReplacePlaceholders(qb.dbType, condition, len(qb.args)+1)
-	qb.conditions = append(qb.conditions, updatedCondition)
-	qb.args = append(qb.args, args...)
+	qb.distinct = true
 	return qb
 }
 
 /*
-WhereIn
+Columns
 
-@ column: Column name for IN clause
-@ values: Values for the IN clause
-@ Return: *QueryBuilder with IN clause added
+@ columns: Column names to add to the select list
+@ Return: *QueryBuilder with the escaped columns appended, replacing the default "*"
 */
-func (qb *QueryBuilder) WhereIn(column string, values []interface{}) *QueryBuilder {
+func (qb *QueryBuilder) Columns(columns ...string) *QueryBuilder {
 	if qb.err != nil {
 		return qb
 	}
-	safeCol, err := EscapeIdentifier(qb.dbType, column)
-	if err != nil {
-		qb.err = err
+	if qb.op != "SELECT" {
+		qb.err = newError(ErrWrongOperation, "Columns() can only be used with SELECT operation")
 		return qb
 	}
-	placeholders := GeneratePlaceholders(qb.dbType, len(qb.args)+1, len(values))
-	qb.conditions = append(qb.conditions, fmt.Sprintf("%s IN (%s)", safeCol, placeholders))
-	qb.args = append(qb.args, values...)
+	for _, col := range columns {
+		safeCol, err := EscapeIdentifier(qb.dbType, col)
+		if err != nil {
+			qb.err = err
+			return qb
+		}
+		qb.addColumn(safeCol)
+	}
 	return qb
 }
 
 /*
-WhereBetween
+ClearColumns
 
-@ column: Column name for BETWEEN clause
-@ start: Start value
-@ end: End value
-@ Return: *QueryBuilder with BETWEEN clause added
+@ Return: *QueryBuilder with the select list reset to "*", which the next added column replaces
 */
-func (qb *QueryBuilder) WhereBetween(column string, start, end interface{}) *QueryBuilder {
+func (qb *QueryBuilder) ClearColumns() *QueryBuilder {
+	if qb.err != nil {
+		return qb
+	}
+	for i := range qb.columnArgs {
+		qb.columnArgs[i] = nil
+	}
+	qb.columnArgs = qb.columnArgs[:0]
+	qb.columns = append(qb.columns[:0], "*")
+	qb.starColumns = true
+	return qb
+}
+
+// addColumn appends a rendered select-list entry, dropping the "*" that
+// NewQueryBuilder puts in when it was given no columns.
+func (qb *QueryBuilder) addColumn(column string) {
+	if qb.starColumns {
+		qb.columns = qb.columns[:0]
+		qb.starColumns = false
+	}
+	qb.columns = append(qb.columns, column)
+}
+
+// Aggregate adds an aggregate function to SELECT queries (COUNT, SUM, AVG, etc.).
+// SQL injection safe with automatic identifier escaping.
+func (qb *QueryBuilder) Aggregate(function, column string) *QueryBuilder {
 	if qb.err != nil {
 		return qb
 	}
@@ -251,240 +489,2741 @@ func (qb *QueryBuilder) WhereBetween(column string, start, end interface{}) *Que
 		qb.err = err
 		return qb
 	}
-	placeholders := GeneratePlaceholders(qb.dbType, len(qb.args)+1, 2)
-	placeholderSlices := strings.Split(placeholders, ", ")
-	if len(placeholderSlices) != 2 {
-		qb.err = fmt.Errorf("failed to generate placeholders for BETWEEN")
-		return qb
-	}
-	qb.conditions = append(qb.conditions, fmt.Sprintf("%s BETWEEN %s AND %s", safeCol, placeholderSlices[0], placeholderSlices[1]))
-	qb.args = append(qb.args, start, end)
+	qb.addColumn(fmt.Sprintf("%s(%s)", function, safeCol))
 	return qb
 }
 
 /*
-AddWhereIfNotEmpty
+CountDistinct
 
-@ column: Column name
-@ value: arguments
-@ Return: *QueryBuilder
+@ column: Column whose distinct values are counted
+@ alias: Alias for the count column, or "" for none
+@ Return: *QueryBuilder with COUNT(DISTINCT column) AS alias added
 */
-func (qb *QueryBuilder) AddWhereIfNotEmpty(condition string, value interface{}) *QueryBuilder {
-	if value == nil {
+func (qb *QueryBuilder) CountDistinct(column, alias string) *QueryBuilder {
+	if qb.err != nil {
 		return qb
 	}
-
-	switch v := value.(type) {
-	case string:
-		if v == "" {
-			return qb
-		}
-	case *string:
-		if v == nil || *v == "" {
+	safeCol, err := EscapeIdentifier(qb.dbType, column)
+	if err != nil {
+		qb.err = err
+		return qb
+	}
+	expr := fmt.Sprintf("COUNT(DISTINCT %s)", safeCol)
+	if alias != "" {
+		safeAlias, err := EscapeIdentifier(qb.dbType, alias)
+		if err != nil {
+			qb.err = err
 			return qb
 		}
-		// 필요에 따라 다른 타입도 처리
+		expr += " AS " + safeAlias
 	}
-
-	return qb.Where(condition, value)
+	qb.addColumn(expr)
+	return qb
 }
 
 /*
-GroupBy
+SelectCoalesce
 
-@ columns: Columns for GROUP BY clause
-@ Return: *QueryBuilder with GROUP BY clause added
+@ column: Column whose NULLs are replaced
+@ fallback: Value bound for rows where column is NULL
+@ alias: Alias for the expression, or "" for none
+@ Return: *QueryBuilder with COALESCE(column, placeholder) AS alias added, its arg bound ahead of WHERE args
 */
-func (qb *QueryBuilder) GroupBy(columns ...string) *QueryBuilder {
+func (qb *QueryBuilder) SelectCoalesce(column string, fallback interface{}, alias string) *QueryBuilder {
 	if qb.err != nil {
 		return qb
 	}
-	for _, col := range columns {
-		safeCol, err := EscapeIdentifier(qb.dbType, col)
+	safeCol, err := EscapeIdentifier(qb.dbType, column)
+	if err != nil {
+		qb.err = err
+		return qb
+	}
+	expr := fmt.Sprintf("COALESCE(%s, %s)", safeCol, qb.dialect.Placeholder(len(qb.columnArgs)+1))
+	if alias != "" {
+		safeAlias, err := EscapeIdentifier(qb.dbType, alias)
 		if err != nil {
 			qb.err = err
 			return qb
 		}
-		qb.groupBy = append(qb.groupBy, safeCol)
+		expr += " AS " + safeAlias
 	}
+	qb.addColumn(expr)
+	qb.columnArgs = append(qb.columnArgs, fallback)
 	return qb
 }
 
 /*
-Having
+SelectSubquery
 
-@ condition: HAVING clause condition with placeholders
-@ args: Query parameters for HAVING clause
-@ Return: *QueryBuilder with HAVING clause added
+@ sub: SELECT builder for the scalar subquery, built with the same database type
+@ alias: Alias for the subquery column
+@ Return: *QueryBuilder selecting (subquery) AS alias, with its args bound ahead of JOIN and WHERE args
 */
-func (qb *QueryBuilder) Having(condition string, args ...interface{}) *QueryBuilder {
+func (qb *QueryBuilder) SelectSubquery(sub *QueryBuilder, alias string) *QueryBuilder {
 	if qb.err != nil {
 		return qb
 	}
-	updatedCondition := ReplacePlaceholders(qb.dbType, condition, len(qb.args)+1)
-	qb.having = append(qb.having, updatedCondition)
-	qb.args = append(qb.args, args...)
+	if qb.op != "SELECT" {
+		qb.err = newError(ErrWrongOperation, "SelectSubquery() can only be used with SELECT operation")
+		return qb
+	}
+	subQuery, subArgs, err := qb.buildSubquery("SelectSubquery", sub)
+	if err != nil {
+		qb.err = err
+		return qb
+	}
+	safeAlias, err := EscapeIdentifier(qb.dbType, alias)
+	if err != nil {
+		qb.err = err
+		return qb
+	}
+	// The subquery is numbered from 1; move it past select-list args already bound
+	subQuery = qb.offsetPlaceholders([]string{subQuery}, len(qb.columnArgs))[0]
+	qb.addColumn("("+subQuery+") AS "+safeAlias)
+	qb.columnArgs = append(qb.columnArgs, subArgs...)
 	return qb
 }
 
+// buildSubquery builds sub for embedding in qb, surfacing its chain error and
+// rejecting subqueries that aren't SELECTs for the same database type.
+func (qb *QueryBuilder) buildSubquery(method string, sub *QueryBuilder) (string, []interface{}, error) {
+	if sub == nil {
+		return "", nil, fmt.Errorf("%s() requires a subquery", method)
+	}
+	if sub.dbType != qb.dbType {
+		return "", nil, fmt.Errorf("subquery database type %s does not match %s", sub.dbType, qb.dbType)
+	}
+	if sub.op != "SELECT" {
+		return "", nil, newError(ErrWrongOperation, "%s() requires a SELECT subquery", method)
+	}
+	if sub.PlaceholderStyle() != qb.PlaceholderStyle() {
+		return "", nil, newError(ErrPlaceholderMismatch, "subquery placeholder style %s does not match %s", sub.PlaceholderStyle(), qb.PlaceholderStyle())
+	}
+	query, args, err := sub.Build()
+	if err != nil {
+		return "", nil, err
+	}
+	// A terminator would end the outer statement early
+	return strings.TrimSuffix(query, ";"), args, nil
+}
+
 /*
-OrderBy
+PercentileCont
 
-@ column: Column name to order by
-@ direction: Order direction ("ASC" or "DESC")
-@ allowedColumns: Map of allowed columns for ordering
-@ Return: *QueryBuilder with ORDER BY clause added
+@ fraction: Percentile to compute, between 0 and 1 (0.5 for the median)
+@ orderColumn: Column whose values are ranked
+@ alias: Alias for the computed column
+@ Return: *QueryBuilder with PERCENTILE_CONT(...) WITHIN GROUP (ORDER BY ...) added (PostgreSQL only)
 */
-func (qb *QueryBuilder) OrderBy(column, direction string, allowedColumns map[string]bool) *QueryBuilder {
+func (qb *QueryBuilder) PercentileCont(fraction float64, orderColumn, alias string) *QueryBuilder {
 	if qb.err != nil {
 		return qb
 	}
-	direction = ValidateDirection(direction)
-	if allowedColumns != nil {
-		if _, ok := allowedColumns[column]; !ok {
-			column = "id"
-		}
+	if qb.dbType != PostgreSQL && qb.dbType != Redshift {
+		qb.err = fmt.Errorf("PercentileCont() is not supported for %s", qb.dbType)
+		return qb
 	}
-	safeCol, err := EscapeIdentifier(qb.dbType, column)
+	if fraction < 0 || fraction > 1 {
+		qb.err = fmt.Errorf("percentile fraction must be between 0 and 1, got %v", fraction)
+		return qb
+	}
+	safeCol, err := EscapeIdentifier(qb.dbType, orderColumn)
 	if err != nil {
 		qb.err = err
 		return qb
 	}
-	qb.orderBy = fmt.Sprintf("%s %s", safeCol, direction)
+	safeAlias, err := EscapeIdentifier(qb.dbType, alias)
+	if err != nil {
+		qb.err = err
+		return qb
+	}
+	qb.addColumn(fmt.Sprintf("PERCENTILE_CONT(%s) WITHIN GROUP (ORDER BY %s) AS %s",
+		strconv.FormatFloat(fraction, 'f', -1, 64), safeCol, safeAlias))
 	return qb
 }
 
-/*
-Limit
-
-@ limit: Maximum number of rows to return
-@ Return: *QueryBuilder with LIMIT set
-*/
-func (qb *QueryBuilder) Limit(limit int) *QueryBuilder {
+// LeftJoin adds a LEFT JOIN clause to the query.
+// Table names are automatically escaped for security.
+func (qb *QueryBuilder) LeftJoin(joinTable, onCondition string) *QueryBuilder {
 	if qb.err != nil {
 		return qb
 	}
-	qb.limit = limit
+	safeTable, err := qb.escapeTable(joinTable)
+	if err != nil {
+		qb.err = err
+		return qb
+	}
+	qb.joins = append(qb.joins, fmt.Sprintf("LEFT JOIN %s ON %s", safeTable, onCondition))
 	return qb
 }
 
 /*
-Offset
+InnerJoin
 
-@ offset: Number of rows to skip
-@ Return: *QueryBuilder with OFFSET set
+@ joinTable: Table name to join
+@ onCondition: Join condition
+@ Return: *QueryBuilder with INNER JOIN added
 */
-func (qb *QueryBuilder) Offset(offset int) *QueryBuilder {
+func (qb *QueryBuilder) InnerJoin(joinTable, onCondition string) *QueryBuilder {
 	if qb.err != nil {
 		return qb
 	}
-	qb.offset = offset
+	safeTable, err := qb.escapeTable(joinTable)
+	if err != nil {
+		qb.err = err
+		return qb
+	}
+	qb.joins = append(qb.joins, fmt.Sprintf("INNER JOIN %s ON %s", safeTable, onCondition))
 	return qb
 }
 
 /*
-Values
+RightJoin
 
-@ data: Map of column names to values for INSERT
+@ joinTable: Table name to join
+@ onCondition: Join condition
+@ Return: *QueryBuilder with RIGHT JOIN added
+*/
+func (qb *QueryBuilder) RightJoin(joinTable, onCondition string) *QueryBuilder {
+	if qb.err != nil {
+		return qb
+	}
+	safeTable, err := qb.escapeTable(joinTable)
+	if err != nil {
+		qb.err = err
+		return qb
+	}
+	qb.joins = append(qb.joins, fmt.Sprintf("RIGHT JOIN %s ON %s", safeTable, onCondition))
+	return qb
+}
+
+/*
+CrossJoinLateral
+
+@ sub: SELECT builder for the lateral subquery; it may reference earlier FROM items
+@ alias: Alias for the subquery
+@ Return: *QueryBuilder with CROSS JOIN LATERAL (subquery) AS alias added (PostgreSQL only)
+*/
+func (qb *QueryBuilder) CrossJoinLateral(sub *QueryBuilder, alias string) *QueryBuilder {
+	if qb.err != nil {
+		return qb
+	}
+	if qb.dbType != PostgreSQL {
+		qb.err = fmt.Errorf("CrossJoinLateral() is not supported for %s", qb.dbType)
+		return qb
+	}
+	subQuery, subArgs, err := qb.buildSubquery("CrossJoinLateral", sub)
+	if err != nil {
+		qb.err = err
+		return qb
+	}
+	safeAlias, err := EscapeIdentifier(qb.dbType, alias)
+	if err != nil {
+		qb.err = err
+		return qb
+	}
+	// Join fragments share their own numbering; move the subquery past earlier join args
+	subQuery = qb.offsetPlaceholders([]string{subQuery}, len(qb.joinArgs))[0]
+	qb.joins = append(qb.joins, fmt.Sprintf("CROSS JOIN LATERAL (%s) AS %s", subQuery, safeAlias))
+	qb.joinArgs = append(qb.joinArgs, subArgs...)
+	return qb
+}
+
+/*
+JoinValues
+
+@ rows: Literal rows of the lookup list, each with one value per column alias
+@ alias: Alias of the lookup list
+@ columnAliases: Column names of the lookup list
+@ onCondition: Join condition
+@ Return: *QueryBuilder with a JOIN against a bound VALUES list added
+*/
+func (qb *QueryBuilder) JoinValues(rows [][]interface{}, alias string, columnAliases []string, onCondition string) *QueryBuilder {
+	if qb.err != nil {
+		return qb
+	}
+	if len(rows) == 0 || len(columnAliases) == 0 {
+		qb.err = fmt.Errorf("JoinValues() requires at least one row and one column alias")
+		return qb
+	}
+	safeAlias, err := EscapeIdentifier(qb.dbType, alias)
+	if err != nil {
+		qb.err = err
+		return qb
+	}
+	safeColumns := make([]string, len(columnAliases))
+	for i, col := range columnAliases {
+		safeCol, err := EscapeIdentifier(qb.dbType, col)
+		if err != nil {
+			qb.err = err
+			return qb
+		}
+		safeColumns[i] = safeCol
+	}
+
+	var valueRows []string
+	var args []interface{}
+	for i, row := range rows {
+		if len(row) != len(columnAliases) {
+			qb.err = fmt.Errorf("JoinValues() row %d has %d values, expected %d", i, len(row), len(columnAliases))
+			return qb
+		}
+		placeholders := generatePlaceholders(qb.dialect, len(qb.joinArgs)+len(args)+1, len(row))
+		args = append(args, row...)
+		switch qb.dbType {
+		case PostgreSQL, SQLServer:
+			valueRows = append(valueRows, "("+placeholders+")")
+		case Mysql:
+			valueRows = append(valueRows, "ROW("+placeholders+")")
+		default:
+			// Dialects without derived column lists get a UNION ALL of aliased SELECTs
+			phs := strings.Split(placeholders, ", ")
+			if i == 0 {
+				for j := range phs {
+					phs[j] += " AS " + safeColumns[j]
+				}
+			}
+			valueRows = append(valueRows, "SELECT "+strings.Join(phs, ", "))
+		}
+	}
+
+	var join string
+	switch qb.dbType {
+	case PostgreSQL, Mysql, SQLServer:
+		join = fmt.Sprintf("JOIN (VALUES %s) AS %s(%s) ON %s",
+			strings.Join(valueRows, ", "), safeAlias, strings.Join(safeColumns, ", "), onCondition)
+	default:
+		join = fmt.Sprintf("JOIN (%s) AS %s ON %s", strings.Join(valueRows, " UNION ALL "), safeAlias, onCondition)
+	}
+	qb.joins = append(qb.joins, join)
+	qb.joinArgs = append(qb.joinArgs, args...)
+	return qb
+}
+
+// Where adds a WHERE condition with parameter binding.
+// Automatically handles database-specific placeholder formats ($N for PostgreSQL, ? for MySQL/MariaDB).
+// SQL injection safe through proper parameter binding.
+func (qb *QueryBuilder) Where(condition string, args ...interface{}) *QueryBuilder {
+	if qb.err != nil {
+		return qb
+	}
+	condition, args, err := expandSliceArgs(condition, args)
+	if err != nil {
+		qb.err = err
+		return qb
+	}
+	updatedCondition := replacePlaceholders(qb.dialect, condition, len(qb.args)+1)
+	qb.conditions = append(qb.conditions, updatedCondition)
+	qb.args = append(qb.args, args...)
+	return qb
+}
+
+// expandSliceArgs rewrites each "?" bound to a slice into one "?" per element and
+// flattens the slice into args, so Where("id IN (?)", ids) works like WhereIn.
+// []byte is left alone since drivers bind it as a single binary value.
+func expandSliceArgs(condition string, args []interface{}) (string, []interface{}, error) {
+	hasSlice := false
+	for _, arg := range args {
+		if isExpandableSlice(arg) {
+			hasSlice = true
+			break
+		}
+	}
+	if !hasSlice {
+		return condition, args, nil
+	}
+	if count := strings.Count(condition, "?"); count != len(args) {
+		return "", nil, fmt.Errorf("condition has %d placeholders but %d args", count, len(args))
+	}
+	var result strings.Builder
+	expanded := make([]interface{}, 0, len(args))
+	next := 0
+	for i := 0; i < len(condition); i++ {
+		if condition[i] != '?' {
+			result.WriteByte(condition[i])
+			continue
+		}
+		arg := args[next]
+		next++
+		if !isExpandableSlice(arg) {
+			result.WriteByte('?')
+			expanded = append(expanded, arg)
+			continue
+		}
+		values := reflect.ValueOf(arg)
+		if values.Len() == 0 {
+			return "", nil, fmt.Errorf("empty slice bound to placeholder %d", next)
+		}
+		for j := 0; j < values.Len(); j++ {
+			if j > 0 {
+				result.WriteString(", ")
+			}
+			result.WriteByte('?')
+			expanded = append(expanded, values.Index(j).Interface())
+		}
+	}
+	return result.String(), expanded, nil
+}
+
+// isExpandableSlice reports whether arg is a list to spread over placeholders.
+// Arrays, byte slices such as json.RawMessage or net.IP, and driver.Valuer types
+// are single values to the driver and are bound as they are.
+func isExpandableSlice(arg interface{}) bool {
+	if arg == nil {
+		return false
+	}
+	if _, ok := arg.(driver.Valuer); ok {
+		return false
+	}
+	t := reflect.TypeOf(arg)
+	return t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8
+}
+
+/*
+WhereRaw
+
+@ condition: Raw WHERE fragment with "?" placeholders, appended verbatim
+@ args: Query parameters for the fragment, bound as-is
+@ Return: *QueryBuilder with condition added
+
+WhereRaw makes no safety guarantees about the fragment: nothing in it is escaped
+or validated, and slice args are not expanded. Only placeholder numbering and
+arg tracking are managed. Never build the fragment from user input.
+*/
+func (qb *QueryBuilder) WhereRaw(condition string, args ...interface{}) *QueryBuilder {
+	if qb.err != nil {
+		return qb
+	}
+	qb.conditions = append(qb.conditions, replacePlaceholders(qb.dialect, condition, len(qb.args)+1))
+	qb.args = append(qb.args, args...)
+	return qb
+}
+
+/*
+WhereColumn
+
+@ left: Column on the left side (e.g. "a.created_at")
+@ operator: Comparison operator (=, <>, !=, <, <=, > or >=)
+@ right: Column on the right side
+@ Return: *QueryBuilder with the column comparison added, binding no args
+*/
+func (qb *QueryBuilder) WhereColumn(left, operator, right string) *QueryBuilder {
+	if qb.err != nil {
+		return qb
+	}
+	if !comparisonOperators[operator] {
+		qb.err = fmt.Errorf("unsupported comparison operator: %s", operator)
+		return qb
+	}
+	safeLeft, err := EscapeIdentifier(qb.dbType, left)
+	if err != nil {
+		qb.err = err
+		return qb
+	}
+	safeRight, err := EscapeIdentifier(qb.dbType, right)
+	if err != nil {
+		qb.err = err
+		return qb
+	}
+	qb.conditions = append(qb.conditions, fmt.Sprintf("%s %s %s", safeLeft, operator, safeRight))
+	return qb
+}
+
+/*
+WhereJSONContains
+
+@ column: JSON/JSONB column
+@ path: Dot-separated key path inside the document (e.g. "address.city"), or "" for the whole document
+@ value: JSON-encoded value to look for, bound as a parameter
+@ Return: *QueryBuilder with col @> $N / col->'path' = $N (PostgreSQL) or JSON_CONTAINS(col, ?, '$.path') (MySQL/MariaDB)
+*/
+func (qb *QueryBuilder) WhereJSONContains(column string, path string, value interface{}) *QueryBuilder {
+	if qb.err != nil {
+		return qb
+	}
+	safeCol, err := EscapeIdentifier(qb.dbType, column)
+	if err != nil {
+		qb.err = err
+		return qb
+	}
+	// The path is inlined as a literal, so only plain key names are accepted
+	var keys []string
+	if path != "" {
+		keys = strings.Split(path, ".")
+		for _, key := range keys {
+			if key == "" || strings.IndexFunc(key, func(r rune) bool {
+				return !(r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9'))
+			}) >= 0 {
+				qb.err = fmt.Errorf("invalid JSON path: %q", path)
+				return qb
+			}
+		}
+	}
+	placeholder := qb.dialect.Placeholder(len(qb.args) + 1)
+	var condition string
+	switch qb.dbType {
+	case PostgreSQL:
+		if len(keys) == 0 {
+			condition = fmt.Sprintf("%s @> %s", safeCol, placeholder)
+		} else {
+			condition = fmt.Sprintf("%s->'%s' = %s", safeCol, strings.Join(keys, "'->'"), placeholder)
+		}
+	case MariaDB, Mysql:
+		if len(keys) == 0 {
+			condition = fmt.Sprintf("JSON_CONTAINS(%s, %s)", safeCol, placeholder)
+		} else {
+			condition = fmt.Sprintf("JSON_CONTAINS(%s, %s, '$.%s')", safeCol, placeholder, path)
+		}
+	default:
+		qb.err = fmt.Errorf("WhereJSONContains() is not supported for %s", qb.dbType)
+		return qb
+	}
+	qb.conditions = append(qb.conditions, condition)
+	qb.args = append(qb.args, value)
+	return qb
+}
+
+/*
+WhereAny
+
+@ column: Column compared against each array element
+@ value: Array value bound as a single parameter (e.g. pq.Array(ids))
+@ Return: *QueryBuilder with col = ANY($N) added (PostgreSQL only)
+*/
+func (qb *QueryBuilder) WhereAny(column string, value interface{}) *QueryBuilder {
+	return qb.whereArray("WhereAny", column, "%s = ANY(%s)", value)
+}
+
+/*
+WhereArrayOverlap
+
+@ column: Array column
+@ value: Array value bound as a single parameter (e.g. pq.Array(tags))
+@ Return: *QueryBuilder with col && $N added (PostgreSQL only)
+*/
+func (qb *QueryBuilder) WhereArrayOverlap(column string, value interface{}) *QueryBuilder {
+	return qb.whereArray("WhereArrayOverlap", column, "%s && %s", value)
+}
+
+func (qb *QueryBuilder) whereArray(method, column, format string, value interface{}) *QueryBuilder {
+	if qb.err != nil {
+		return qb
+	}
+	if qb.dbType != PostgreSQL {
+		qb.err = fmt.Errorf("%s() is only supported for PostgreSQL", method)
+		return qb
+	}
+	safeCol, err := EscapeIdentifier(qb.dbType, column)
+	if err != nil {
+		qb.err = err
+		return qb
+	}
+	qb.conditions = append(qb.conditions, fmt.Sprintf(format, safeCol, qb.dialect.Placeholder(len(qb.args)+1)))
+	qb.args = append(qb.args, value)
+	return qb
+}
+
+/*
+WhereFullText
+
+@ columns: Columns searched together
+@ query: Search text, bound as a parameter
+@ Return: *QueryBuilder with to_tsvector(...) @@ plainto_tsquery($N) (PostgreSQL) or MATCH(...) AGAINST (? IN NATURAL LANGUAGE MODE) (MySQL/MariaDB)
+
+MySQL needs a FULLTEXT index covering exactly these columns.
+*/
+func (qb *QueryBuilder) WhereFullText(columns []string, query string) *QueryBuilder {
+	if qb.err != nil {
+		return qb
+	}
+	if len(columns) == 0 {
+		qb.err = fmt.Errorf("WhereFullText() requires at least one column")
+		return qb
+	}
+	safeCols := make([]string, len(columns))
+	for i, col := range columns {
+		safeCol, err := EscapeIdentifier(qb.dbType, col)
+		if err != nil {
+			qb.err = err
+			return qb
+		}
+		safeCols[i] = safeCol
+	}
+	placeholder := qb.dialect.Placeholder(len(qb.args) + 1)
+	var condition string
+	switch qb.dbType {
+	case PostgreSQL:
+		document := safeCols[0]
+		if len(safeCols) > 1 {
+			document = "concat_ws(' ', " + strings.Join(safeCols, ", ") + ")"
+		}
+		condition = fmt.Sprintf("to_tsvector(%s) @@ plainto_tsquery(%s)", document, placeholder)
+	case MariaDB, Mysql:
+		condition = fmt.Sprintf("MATCH(%s) AGAINST (%s IN NATURAL LANGUAGE MODE)", strings.Join(safeCols, ", "), placeholder)
+	default:
+		qb.err = fmt.Errorf("WhereFullText() is not supported for %s", qb.dbType)
+		return qb
+	}
+	qb.conditions = append(qb.conditions, condition)
+	qb.args = append(qb.args, query)
+	return qb
+}
+
+/*
+WhereNamed
+
+@ condition: WHERE condition with :name binds (e.g. "start <= :day AND end >= :day")
+@ binds: Values keyed by bind name, without the leading colon
+@ Return: *QueryBuilder with condition added
+*/
+func (qb *QueryBuilder) WhereNamed(condition string, binds map[string]interface{}) *QueryBuilder {
+	if qb.err != nil {
+		return qb
+	}
+	// $N dialects can refer to the same arg twice; "?" dialects repeat it
+	numbered := numberedPlaceholders(qb.dialect)
+	bound := make(map[string]string)
+	var result strings.Builder
+	inLiteral := false
+	for i := 0; i < len(condition); i++ {
+		char := condition[i]
+		if char == '\'' {
+			inLiteral = !inLiteral
+		}
+		if inLiteral || char != ':' {
+			result.WriteByte(char)
+			continue
+		}
+		// Leave PostgreSQL casts such as value::int alone
+		if i+1 < len(condition) && condition[i+1] == ':' {
+			result.WriteString("::")
+			i++
+			continue
+		}
+		j := i + 1
+		for j < len(condition) && isBindNameChar(condition[j], j == i+1) {
+			j++
+		}
+		if j == i+1 {
+			result.WriteByte(char)
+			continue
+		}
+		name := condition[i+1 : j]
+		value, ok := binds[name]
+		if !ok {
+			qb.err = fmt.Errorf("no value bound for :%s", name)
+			return qb
+		}
+		placeholder, seen := bound[name]
+		if !seen || !numbered {
+			placeholder = qb.dialect.Placeholder(len(qb.args) + 1)
+			bound[name] = placeholder
+			qb.args = append(qb.args, value)
+		}
+		result.WriteString(placeholder)
+		i = j - 1
+	}
+	qb.conditions = append(qb.conditions, result.String())
+	return qb
+}
+
+// isBindNameChar reports whether c can appear in a :name bind; digits can't start one.
+func isBindNameChar(c byte, first bool) bool {
+	if c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') {
+		return true
+	}
+	return !first && c >= '0' && c <= '9'
+}
+
+/*
+WhereEquals
+
+@ filters: Column/value pairs that must all match, in sorted column order; a nil value matches IS NULL
+@ Return: *QueryBuilder with one equality condition per column added
+*/
+func (qb *QueryBuilder) WhereEquals(filters map[string]interface{}) *QueryBuilder {
+	if qb.err != nil {
+		return qb
+	}
+	keys := make([]string, 0, len(filters))
+	for key := range filters {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		safeCol, err := EscapeIdentifier(qb.dbType, key)
+		if err != nil {
+			qb.err = err
+			return qb
+		}
+		value := filters[key]
+		if value == nil {
+			qb.conditions = append(qb.conditions, safeCol+" IS NULL")
+			continue
+		}
+		qb.conditions = append(qb.conditions, safeCol+" = "+qb.dialect.Placeholder(len(qb.args)+1))
+		qb.args = append(qb.args, value)
+	}
+	return qb
+}
+
+/*
+WhereNullSafeEquals
+
+@ column: Column name to compare
+@ value: Value to compare with; nil matches NULL instead of never matching
+@ Return: *QueryBuilder with a NULL-safe equality condition added
+
+MySQL/MariaDB use <=>, SQLite uses IS, and other dialects use IS NOT DISTINCT FROM.
+*/
+func (qb *QueryBuilder) WhereNullSafeEquals(column string, value interface{}) *QueryBuilder {
+	if qb.err != nil {
+		return qb
+	}
+	safeCol, err := EscapeIdentifier(qb.dbType, column)
+	if err != nil {
+		qb.err = err
+		return qb
+	}
+	operator := "IS NOT DISTINCT FROM"
+	switch qb.dbType {
+	case MariaDB, Mysql:
+		operator = "<=>"
+	case SQLite:
+		operator = "IS"
+	}
+	qb.conditions = append(qb.conditions, fmt.Sprintf("%s %s %s", safeCol, operator, qb.dialect.Placeholder(len(qb.args)+1)))
+	qb.args = append(qb.args, value)
+	return qb
+}
+
+/*
+WithoutSoftDeleted
+
+@ column: Soft-delete marker column, e.g. "deleted_at"
+@ Return: *QueryBuilder with column IS NULL added, keeping only live rows
+*/
+func (qb *QueryBuilder) WithoutSoftDeleted(column string) *QueryBuilder {
+	return qb.whereNullness(column, "IS NULL")
+}
+
+/*
+OnlySoftDeleted
+
+@ column: Soft-delete marker column, e.g. "deleted_at"
+@ Return: *QueryBuilder with column IS NOT NULL added, keeping only deleted rows
+*/
+func (qb *QueryBuilder) OnlySoftDeleted(column string) *QueryBuilder {
+	return qb.whereNullness(column, "IS NOT NULL")
+}
+
+// whereNullness adds an argument-free "column IS [NOT] NULL" condition.
+func (qb *QueryBuilder) whereNullness(column, predicate string) *QueryBuilder {
+	if qb.err != nil {
+		return qb
+	}
+	safeCol, err := EscapeIdentifier(qb.dbType, column)
+	if err != nil {
+		qb.err = err
+		return qb
+	}
+	qb.conditions = append(qb.conditions, safeCol+" "+predicate)
+	return qb
+}
+
+/*
+WhereIn
+
+@ column: Column name for IN clause
+@ values: Values for the IN clause
+@ Return: *QueryBuilder with IN clause added; no values adds 1 = 0, since IN () is invalid SQL and matches nothing
+*/
+func (qb *QueryBuilder) WhereIn(column string, values []interface{}) *QueryBuilder {
+	if qb.err != nil {
+		return qb
+	}
+	safeCol, err := EscapeIdentifier(qb.dbType, column)
+	if err != nil {
+		qb.err = err
+		return qb
+	}
+	if len(values) == 0 {
+		qb.conditions = append(qb.conditions, "1 = 0")
+		return qb
+	}
+	placeholders := generatePlaceholders(qb.dialect, len(qb.args)+1, len(values))
+	qb.conditions = append(qb.conditions, fmt.Sprintf("%s IN (%s)", safeCol, placeholders))
+	qb.args = append(qb.args, values...)
+	return qb
+}
+
+/*
+WhereInSubquery
+
+@ column: Column name for IN clause
+@ sub: SELECT builder for the subquery, built with the same database type
+@ Return: *QueryBuilder with column IN (subquery) clause added
+*/
+func (qb *QueryBuilder) WhereInSubquery(column string, sub *QueryBuilder) *QueryBuilder {
+	if qb.err != nil {
+		return qb
+	}
+	safeCol, err := EscapeIdentifier(qb.dbType, column)
+	if err != nil {
+		qb.err = err
+		return qb
+	}
+	subQuery, subArgs, err := qb.buildSubquery("WhereInSubquery", sub)
+	if err != nil {
+		qb.err = err
+		return qb
+	}
+	subQuery = qb.offsetPlaceholders([]string{subQuery}, len(qb.args))[0]
+	qb.conditions = append(qb.conditions, fmt.Sprintf("%s IN (%s)", safeCol, subQuery))
+	qb.args = append(qb.args, subArgs...)
+	return qb
+}
+
+/*
+WhereInCSV
+
+@ column: Column name for IN clause
+@ csv: Comma-separated values such as an "ids=1,2,3" query parameter
+@ Return: *QueryBuilder with IN clause added for the trimmed, non-empty values, bound as strings
+*/
+func (qb *QueryBuilder) WhereInCSV(column, csv string) *QueryBuilder {
+	var values []interface{}
+	for _, part := range strings.Split(csv, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			values = append(values, part)
+		}
+	}
+	return qb.WhereIn(column, values)
+}
+
+/*
+WhereInTuple
+
+@ columns: Columns compared as a tuple, e.g. (a, b)
+@ rows: Value rows, each with one value per column
+@ Return: *QueryBuilder with (columns) IN ((...), (...)) clause added
+
+Args are bound row by row. SQL Server has no row-value comparison.
+*/
+func (qb *QueryBuilder) WhereInTuple(columns []string, rows [][]interface{}) *QueryBuilder {
+	if qb.err != nil {
+		return qb
+	}
+	if qb.dbType == SQLServer {
+		qb.err = fmt.Errorf("WhereInTuple() is not supported for %s", qb.dbType)
+		return qb
+	}
+	if len(columns) == 0 || len(rows) == 0 {
+		qb.err = fmt.Errorf("WhereInTuple() requires at least one column and one row")
+		return qb
+	}
+	safeCols := make([]string, len(columns))
+	for i, column := range columns {
+		safeCol, err := EscapeIdentifier(qb.dbType, column)
+		if err != nil {
+			qb.err = err
+			return qb
+		}
+		safeCols[i] = safeCol
+	}
+	tuples := make([]string, len(rows))
+	nextIdx := len(qb.args) + 1
+	for i, row := range rows {
+		if len(row) != len(columns) {
+			qb.err = fmt.Errorf("WhereInTuple() row %d has %d values, expected %d", i, len(row), len(columns))
+			return qb
+		}
+		tuples[i] = "(" + generatePlaceholders(qb.dialect, nextIdx, len(row)) + ")"
+		nextIdx += len(row)
+	}
+	qb.conditions = append(qb.conditions, fmt.Sprintf("(%s) IN (%s)", strings.Join(safeCols, ", "), strings.Join(tuples, ", ")))
+	for _, row := range rows {
+		qb.args = append(qb.args, row...)
+	}
+	return qb
+}
+
+/*
+WhereBetween
+
+@ column: Column name for BETWEEN clause
+@ start: Start value
+@ end: End value
+@ Return: *QueryBuilder with BETWEEN clause added
+*/
+func (qb *QueryBuilder) WhereBetween(column string, start, end interface{}) *QueryBuilder {
+	return qb.whereBetween(column, "BETWEEN", start, end)
+}
+
+/*
+WhereNotBetween
+
+@ column: Column name for NOT BETWEEN clause
+@ start: Start value
+@ end: End value
+@ Return: *QueryBuilder with NOT BETWEEN clause added
+*/
+func (qb *QueryBuilder) WhereNotBetween(column string, start, end interface{}) *QueryBuilder {
+	return qb.whereBetween(column, "NOT BETWEEN", start, end)
+}
+
+/*
+WhereDateBetween
+
+@ column: Column name for BETWEEN clause
+@ start: Start of the range, inclusive
+@ end: End of the range, inclusive; must not be before start
+@ Return: *QueryBuilder with BETWEEN clause added, the times bound as-is for the driver to encode
+*/
+func (qb *QueryBuilder) WhereDateBetween(column string, start, end time.Time) *QueryBuilder {
+	if qb.err != nil {
+		return qb
+	}
+	if end.Before(start) {
+		qb.err = fmt.Errorf("WhereDateBetween() end %s is before start %s",
+			end.Format(time.RFC3339), start.Format(time.RFC3339))
+		return qb
+	}
+	return qb.whereBetween(column, "BETWEEN", start, end)
+}
+
+func (qb *QueryBuilder) whereBetween(column, keyword string, start, end interface{}) *QueryBuilder {
+	if qb.err != nil {
+		return qb
+	}
+	safeCol, err := EscapeIdentifier(qb.dbType, column)
+	if err != nil {
+		qb.err = err
+		return qb
+	}
+	startIdx := len(qb.args) + 1
+	qb.conditions = append(qb.conditions, fmt.Sprintf("%s %s %s AND %s",
+		safeCol, keyword, qb.dialect.Placeholder(startIdx), qb.dialect.Placeholder(startIdx+1)))
+	qb.args = append(qb.args, start, end)
+	return qb
+}
+
+/*
+WhereTimeGTE
+
+@ column: Column name to compare
+@ t: Lower bound, inclusive; converted to UTC before binding unless PreserveTimeZone is set
+@ Return: *QueryBuilder with column >= placeholder added
+*/
+func (qb *QueryBuilder) WhereTimeGTE(column string, t time.Time) *QueryBuilder {
+	return qb.whereTime(column, ">=", t)
+}
+
+/*
+WhereTimeGT
+
+@ column: Column name to compare
+@ t: Lower bound, exclusive; converted to UTC before binding unless PreserveTimeZone is set
+@ Return: *QueryBuilder with column > placeholder added
+*/
+func (qb *QueryBuilder) WhereTimeGT(column string, t time.Time) *QueryBuilder {
+	return qb.whereTime(column, ">", t)
+}
+
+/*
+WhereTimeLTE
+
+@ column: Column name to compare
+@ t: Upper bound, inclusive; converted to UTC before binding unless PreserveTimeZone is set
+@ Return: *QueryBuilder with column <= placeholder added
+*/
+func (qb *QueryBuilder) WhereTimeLTE(column string, t time.Time) *QueryBuilder {
+	return qb.whereTime(column, "<=", t)
+}
+
+/*
+WhereTimeLT
+
+@ column: Column name to compare
+@ t: Upper bound, exclusive; converted to UTC before binding unless PreserveTimeZone is set
+@ Return: *QueryBuilder with column < placeholder added
+*/
+func (qb *QueryBuilder) WhereTimeLT(column string, t time.Time) *QueryBuilder {
+	return qb.whereTime(column, "<", t)
+}
+
+/*
+PreserveTimeZone
+
+@ Return: *QueryBuilder whose WhereTime* methods bind times in their own location instead of UTC
+*/
+func (qb *QueryBuilder) PreserveTimeZone() *QueryBuilder {
+	if qb.err != nil {
+		return qb
+	}
+	qb.keepTimeZone = true
+	return qb
+}
+
+// whereTime adds a time comparison, normalizing t to UTC so the database gets the
+// same instant no matter which location the caller built it in.
+func (qb *QueryBuilder) whereTime(column, operator string, t time.Time) *QueryBuilder {
+	if qb.err != nil {
+		return qb
+	}
+	safeCol, err := EscapeIdentifier(qb.dbType, column)
+	if err != nil {
+		qb.err = err
+		return qb
+	}
+	if !qb.keepTimeZone {
+		t = t.UTC()
+	}
+	qb.conditions = append(qb.conditions, fmt.Sprintf("%s %s %s", safeCol, operator, qb.dialect.Placeholder(len(qb.args)+1)))
+	qb.args = append(qb.args, t)
+	return qb
+}
+
+/*
+AddWhereIfNotEmpty
+
+@ column: Column name
+@ value: arguments
+@ Return: *QueryBuilder, unchanged when value is empty
+
+A value is empty when it is nil, an empty string, a zero int, uint or float,
+an empty slice or map, a nil pointer, or a pointer to an empty string.
+false and pointers to zero numbers are real filter values and are kept.
+*/
+func (qb *QueryBuilder) AddWhereIfNotEmpty(condition string, value interface{}) *QueryBuilder {
+	if isEmptyFilterValue(value) {
+		return qb
+	}
+	return qb.Where(condition, value)
+}
+
+/*
+When
+
+@ cond: Whether fn should be applied
+@ fn: Adds the optional clauses, e.g. func(b *QueryBuilder) *QueryBuilder { return b.Where("name = ?", name) }
+@ Return: *QueryBuilder with fn applied when cond is true, unchanged otherwise
+
+fn may add any clause, including joins, so an expensive join can be added only
+when a filter needs it. Join args are numbered separately from WHERE args, so
+placeholders stay correct whether or not fn runs.
+*/
+func (qb *QueryBuilder) When(cond bool, fn func(*QueryBuilder) *QueryBuilder) *QueryBuilder {
+	if qb.err != nil || !cond {
+		return qb
+	}
+	// fn normally returns qb itself; keep an error from any other builder it hands back
+	if result := fn(qb); result != nil && result != qb && result.err != nil {
+		qb.err = result.err
+	}
+	return qb
+}
+
+/*
+ApplyFilters
+
+@ filters: Filters applied in order; nil filters are skipped
+@ Return: *QueryBuilder with every filter's clauses added, stopping at the first error
+*/
+func (qb *QueryBuilder) ApplyFilters(filters ...Filter) *QueryBuilder {
+	for _, filter := range filters {
+		if qb.err != nil {
+			return qb
+		}
+		if filter == nil {
+			continue
+		}
+		if result := filter.Apply(qb); result != nil && result != qb && result.err != nil {
+			qb.err = result.err
+		}
+	}
+	return qb
+}
+
+func isEmptyFilterValue(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	switch v := value.(type) {
+	case string:
+		return v == ""
+	case *string:
+		return v == nil || *v == ""
+	}
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return rv.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return rv.Float() == 0
+	case reflect.Slice, reflect.Map:
+		return rv.Len() == 0
+	case reflect.Ptr, reflect.Interface:
+		return rv.IsNil()
+	}
+	return false
+}
+
+/*
+GroupBy
+
+@ columns: Columns for GROUP BY clause
+@ Return: *QueryBuilder with GROUP BY clause added
+*/
+func (qb *QueryBuilder) GroupBy(columns ...string) *QueryBuilder {
+	if qb.err != nil {
+		return qb
+	}
+	if qb.rollup && (qb.dbType == MariaDB || qb.dbType == Mysql) {
+		qb.err = fmt.Errorf("GroupBy() must be called before GroupByRollup() for %s", qb.dbType)
+		return qb
+	}
+	for _, col := range columns {
+		safeCol, err := EscapeIdentifier(qb.dbType, col)
+		if err != nil {
+			qb.err = err
+			return qb
+		}
+		qb.groupBy = append(qb.groupBy, safeCol)
+	}
+	return qb
+}
+
+/*
+GroupByRollup
+
+@ columns: Columns to group with subtotal rows
+@ Return: *QueryBuilder with GROUP BY ... WITH ROLLUP (MySQL/MariaDB) or GROUP BY ROLLUP (...) added
+*/
+func (qb *QueryBuilder) GroupByRollup(columns ...string) *QueryBuilder {
+	if qb.err != nil {
+		return qb
+	}
+	if qb.rollup {
+		qb.err = fmt.Errorf("GroupByRollup() can only be called once")
+		return qb
+	}
+	if len(columns) == 0 {
+		qb.err = fmt.Errorf("GroupByRollup() requires at least one column")
+		return qb
+	}
+	safeCols := make([]string, len(columns))
+	for i, col := range columns {
+		safeCol, err := EscapeIdentifier(qb.dbType, col)
+		if err != nil {
+			qb.err = err
+			return qb
+		}
+		safeCols[i] = safeCol
+	}
+	switch qb.dbType {
+	case PostgreSQL, Redshift, SQLServer:
+		qb.groupBy = append(qb.groupBy, "ROLLUP ("+strings.Join(safeCols, ", ")+")")
+	case MariaDB, Mysql:
+		// WITH ROLLUP applies to the whole GROUP BY list, so it has to come last
+		qb.groupBy = append(qb.groupBy, strings.Join(safeCols, ", ")+" WITH ROLLUP")
+	default:
+		qb.err = fmt.Errorf("GroupByRollup() is not supported for %s", qb.dbType)
+		return qb
+	}
+	qb.rollup = true
+	return qb
+}
+
+/*
+StrictGroupBy
+
+@ Return: *QueryBuilder whose Build rejects selected columns that are neither grouped nor aggregated
+
+This catches ONLY_FULL_GROUP_BY violations before they reach MySQL. Columns are
+compared as written, so select and group by the same (qualified) name.
+*/
+func (qb *QueryBuilder) StrictGroupBy() *QueryBuilder {
+	if qb.err != nil {
+		return qb
+	}
+	if qb.op != "SELECT" {
+		qb.err = newError(ErrWrongOperation, "StrictGroupBy() can only be used with SELECT operation")
+		return qb
+	}
+	qb.strictGroupBy = true
+	return qb
+}
+
+/*
+Having
+
+@ condition: HAVING clause condition with placeholders
+@ args: Query parameters for HAVING clause
+@ Return: *QueryBuilder with HAVING clause added
+*/
+func (qb *QueryBuilder) Having(condition string, args ...interface{}) *QueryBuilder {
+	if qb.err != nil {
+		return qb
+	}
+	if len(qb.groupBy) == 0 {
+		qb.err = fmt.Errorf("Having() requires GroupBy() to be called first")
+		return qb
+	}
+	updatedCondition := replacePlaceholders(qb.dialect, condition, len(qb.havingArgs)+1)
+	qb.having = append(qb.having, updatedCondition)
+	qb.havingArgs = append(qb.havingArgs, args...)
+	return qb
+}
+
+/*
+HavingRaw
+
+@ condition: Raw HAVING fragment with "?" placeholders, appended verbatim
+@ args: Query parameters for the fragment
+@ Return: *QueryBuilder with HAVING condition added
+
+Like WhereRaw, nothing in the fragment is validated or escaped, and unlike Having
+it does not require GroupBy, so whole-table aggregates can be filtered.
+*/
+func (qb *QueryBuilder) HavingRaw(condition string, args ...interface{}) *QueryBuilder {
+	if qb.err != nil {
+		return qb
+	}
+	qb.having = append(qb.having, replacePlaceholders(qb.dialect, condition, len(qb.havingArgs)+1))
+	qb.havingArgs = append(qb.havingArgs, args...)
+	return qb
+}
+
+// havingAggregates are the aggregate functions accepted by HavingAggregate.
+var havingAggregates = map[string]bool{
+	"COUNT": true,
+	"SUM":   true,
+	"AVG":   true,
+	"MIN":   true,
+	"MAX":   true,
+}
+
+// isAggregateColumn reports whether a selected column is an aggregate call such as
+// SUM("total") or COUNT(DISTINCT "id"), rather than a window function using one.
+func isAggregateColumn(col string) bool {
+	upper := strings.ToUpper(col)
+	if strings.Contains(upper, " OVER ") || strings.Contains(upper, " OVER(") {
+		return false
+	}
+	if strings.HasPrefix(upper, "PERCENTILE_CONT(") {
+		return true
+	}
+	if open := strings.Index(upper, "("); open > 0 {
+		return havingAggregates[upper[:open]]
+	}
+	return false
+}
+
+// comparisonOperators are the operators accepted where callers pass one as a string.
+var comparisonOperators = map[string]bool{
+	"=":  true,
+	"<>": true,
+	"!=": true,
+	"<":  true,
+	"<=": true,
+	">":  true,
+	">=": true,
+}
+
+/*
+HavingAggregate
+
+@ function: Aggregate function (COUNT, SUM, AVG, MIN or MAX)
+@ column: Column to aggregate; "*" is only allowed with COUNT
+@ operator: Comparison operator (=, <>, !=, <, <=, > or >=)
+@ value: Value the aggregate is compared against
+@ Return: *QueryBuilder with HAVING FUNCTION(column) operator ? added
+*/
+func (qb *QueryBuilder) HavingAggregate(function, column, operator string, value interface{}) *QueryBuilder {
+	if qb.err != nil {
+		return qb
+	}
+	function = strings.ToUpper(function)
+	if !havingAggregates[function] {
+		qb.err = fmt.Errorf("unsupported aggregate function: %s", function)
+		return qb
+	}
+	if !comparisonOperators[operator] {
+		qb.err = fmt.Errorf("unsupported comparison operator: %s", operator)
+		return qb
+	}
+	if column == "*" && function != "COUNT" {
+		qb.err = fmt.Errorf("%s(*) is not a valid aggregate", function)
+		return qb
+	}
+	safeCol, err := EscapeIdentifier(qb.dbType, column)
+	if err != nil {
+		qb.err = err
+		return qb
+	}
+	return qb.Having(fmt.Sprintf("%s(%s) %s ?", function, safeCol, operator), value)
+}
+
+/*
+OrderBy
+
+@ column: Column name to order by
+@ direction: Order direction ("ASC" or "DESC")
+@ allowedColumns: Map of allowed columns for ordering
+@ Return: *QueryBuilder with ORDER BY clause added
+*/
+func (qb *QueryBuilder) OrderBy(column, direction string, allowedColumns map[string]bool) *QueryBuilder {
+	if qb.err != nil {
+		return qb
+	}
+	return qb.OrderByWithDefault(column, direction, "id", allowedColumns)
+}
+
+/*
+OrderByWithDefault
+
+@ column: Column name to order by
+@ direction: Order direction ("ASC" or "DESC")
+@ defaultColumn: Column used when column is not allowed, or "" to reject it instead
+@ allowedColumns: Map of allowed columns for ordering
+@ Return: *QueryBuilder with ORDER BY clause added
+
+OrderBy is OrderByWithDefault with "id" as the default column, which suits
+tables keyed by id; pass the table's own key for the others.
+*/
+func (qb *QueryBuilder) OrderByWithDefault(column, direction, defaultColumn string, allowedColumns map[string]bool) *QueryBuilder {
+	if qb.err != nil {
+		return qb
+	}
+	direction = ValidateDirection(direction)
+	safeCol, err := qb.orderColumn(column, defaultColumn, allowedColumns)
+	if err != nil {
+		qb.err = err
+		return qb
+	}
+	qb.orderBy = fmt.Sprintf("%s %s", safeCol, direction)
+	return qb
+}
+
+/*
+OrderByStrict
+
+@ column: Column name to order by
+@ direction: Order direction ("ASC" or "DESC")
+@ allowedColumns: Map of allowed columns for ordering
+@ Return: *QueryBuilder with ORDER BY clause added, or an error naming column if it is not allowed
+*/
+func (qb *QueryBuilder) OrderByStrict(column, direction string, allowedColumns map[string]bool) *QueryBuilder {
+	return qb.OrderByWithDefault(column, direction, "", allowedColumns)
+}
+
+// orderColumn escapes an ORDER BY column, falling back to defaultColumn when
+// allowedColumns is set and doesn't contain it. An empty defaultColumn makes a
+// disallowed column an error.
+func (qb *QueryBuilder) orderColumn(column, defaultColumn string, allowedColumns map[string]bool) (string, error) {
+	if allowedColumns != nil {
+		if _, ok := allowedColumns[column]; !ok {
+			if defaultColumn == "" {
+				return "", newError(ErrInvalidIdentifier, "column %q is not allowed for ordering", column)
+			}
+			column = defaultColumn
+		}
+	}
+	return EscapeIdentifier(qb.dbType, column)
+}
+
+/*
+OrderByNulls
+
+@ column: Column name to order by
+@ direction: Order direction ("ASC" or "DESC")
+@ nulls: Where NULLs sort, "FIRST" or "LAST"
+@ allowedColumns: Map of allowed columns for ordering
+@ Return: *QueryBuilder with ORDER BY col direction NULLS FIRST/LAST, emulated where unsupported
+
+MySQL/MariaDB have no NULLS FIRST/LAST, so an ISNULL(col) sort key is put first;
+SQL Server gets the equivalent CASE expression.
+*/
+func (qb *QueryBuilder) OrderByNulls(column, direction, nulls string, allowedColumns map[string]bool) *QueryBuilder {
+	if qb.err != nil {
+		return qb
+	}
+	nulls = strings.ToUpper(nulls)
+	if nulls != "FIRST" && nulls != "LAST" {
+		qb.err = fmt.Errorf("invalid NULLS ordering: %s (expected FIRST or LAST)", nulls)
+		return qb
+	}
+	direction = ValidateDirection(direction)
+	safeCol, err := qb.orderColumn(column, "id", allowedColumns)
+	if err != nil {
+		qb.err = err
+		return qb
+	}
+	// The null flag is 1 for NULL rows, so ascending puts them last
+	flagDirection := "ASC"
+	if nulls == "FIRST" {
+		flagDirection = "DESC"
+	}
+	switch qb.dbType {
+	case MariaDB, Mysql:
+		qb.orderBy = fmt.Sprintf("ISNULL(%s) %s, %s %s", safeCol, flagDirection, safeCol, direction)
+	case SQLServer:
+		qb.orderBy = fmt.Sprintf("CASE WHEN %s IS NULL THEN 1 ELSE 0 END %s, %s %s", safeCol, flagDirection, safeCol, direction)
+	default:
+		qb.orderBy = fmt.Sprintf("%s %s NULLS %s", safeCol, direction, nulls)
+	}
+	return qb
+}
+
+/*
+OrderByMap
+
+@ sorts: Columns and directions in sort priority order, e.g. parsed from a request's sort spec
+@ allowedColumns: Map of allowed columns for ordering
+@ Return: *QueryBuilder with a multi-column ORDER BY clause, or an error naming the first disallowed column
+*/
+func (qb *QueryBuilder) OrderByMap(sorts []OrderColumn, allowedColumns map[string]bool) *QueryBuilder {
+	if qb.err != nil {
+		return qb
+	}
+	if len(sorts) == 0 {
+		return qb
+	}
+	orderParts := make([]string, 0, len(sorts))
+	for _, spec := range sorts {
+		safeCol, err := qb.orderColumn(spec.Column, "", allowedColumns)
+		if err != nil {
+			qb.err = err
+			return qb
+		}
+		orderParts = append(orderParts, fmt.Sprintf("%s %s", safeCol, ValidateDirection(spec.Direction)))
+	}
+	qb.orderBy = strings.Join(orderParts, ", ")
+	return qb
+}
+
+/*
+OrderByCollate
+
+@ collation: Collation applied to every ORDER BY column (e.g. "en_US" or "utf8mb4_unicode_ci")
+@ sorts: Columns and directions in sort priority order
+@ allowedColumns: Map of allowed columns for ordering
+@ Return: *QueryBuilder with a collated multi-column ORDER BY clause
+*/
+func (qb *QueryBuilder) OrderByCollate(collation string, sorts []OrderColumn, allowedColumns map[string]bool) *QueryBuilder {
+	if qb.err != nil {
+		return qb
+	}
+	collateClause, err := buildCollateClause(qb.dbType, collation)
+	if err != nil {
+		qb.err = err
+		return qb
+	}
+	orderParts := make([]string, 0, len(sorts))
+	for _, spec := range sorts {
+		safeCol, err := qb.orderColumn(spec.Column, "id", allowedColumns)
+		if err != nil {
+			qb.err = err
+			return qb
+		}
+		orderParts = append(orderParts, fmt.Sprintf("%s %s %s", safeCol, collateClause, ValidateDirection(spec.Direction)))
+	}
+	qb.orderBy = strings.Join(orderParts, ", ")
+	return qb
+}
+
+/*
+PushDownLimit
+
+@ Return: *QueryBuilder that applies ORDER BY/LIMIT/OFFSET to the driving table in a subquery before joining
+
+The rewrite renders FROM (SELECT * FROM t ORDER BY ... LIMIT n) AS t JOIN ...
+and only applies when the query has joins and a limit. It returns the same rows
+only when every join is a to-one join that keeps all driving rows and WHERE,
+GROUP BY and HAVING don't filter driving rows out; otherwise fewer rows than
+the limit may come back, because filtering happens after the limit.
+*/
+func (qb *QueryBuilder) PushDownLimit() *QueryBuilder {
+	if qb.err != nil {
+		return qb
+	}
+	if qb.op != "SELECT" {
+		qb.err = newError(ErrWrongOperation, "PushDownLimit() can only be used with SELECT operation")
+		return qb
+	}
+	qb.pushDownLimit = true
+	return qb
+}
+
+/*
+ForUpdateOf
+
+@ tables: Unqualified tables or join aliases whose rows are locked (e.g. "orders", "o")
+@ Return: *QueryBuilder with FOR UPDATE OF t1, t2 added (PostgreSQL and MySQL 8)
+
+Rows read from tables not listed stay unlocked, so a SELECT joining lookup tables
+only locks the rows it will update. Repeated calls extend the list.
+*/
+func (qb *QueryBuilder) ForUpdateOf(tables ...string) *QueryBuilder {
+	if qb.err != nil {
+		return qb
+	}
+	if qb.op != "SELECT" {
+		qb.err = newError(ErrWrongOperation, "ForUpdateOf() can only be used with SELECT operation")
+		return qb
+	}
+	if qb.dbType != PostgreSQL && qb.dbType != Mysql {
+		qb.err = fmt.Errorf("ForUpdateOf() is not supported for %s", qb.dbType)
+		return qb
+	}
+	if len(tables) == 0 {
+		qb.err = fmt.Errorf("ForUpdateOf() requires at least one table")
+		return qb
+	}
+	for _, table := range tables {
+		// OF takes a bare table name or alias; "orders o" and "app.orders" are rejected
+		if len(strings.Fields(table)) != 1 || strings.Contains(table, ".") {
+			qb.err = newError(ErrInvalidIdentifier, "invalid lock table: %q", table)
+			return qb
+		}
+		safeTable, err := EscapeIdentifier(qb.dbType, table)
+		if err != nil {
+			qb.err = err
+			return qb
+		}
+		qb.lockOf = append(qb.lockOf, safeTable)
+	}
+	return qb
+}
+
+/*
+LockWait
+
+@ d: Maximum time a locking read waits for row locks
+@ Return: *QueryBuilder with FOR UPDATE WAIT n added (MariaDB only)
+
+PostgreSQL has no per-statement wait clause; run SET LOCAL lock_timeout = '<n>ms'
+in the same transaction before the SELECT ... FOR UPDATE instead. MySQL bounds
+the wait through the innodb_lock_wait_timeout session variable.
+*/
+func (qb *QueryBuilder) LockWait(d time.Duration) *QueryBuilder {
+	if qb.err != nil {
+		return qb
+	}
+	if qb.op != "SELECT" {
+		qb.err = newError(ErrWrongOperation, "LockWait() can only be used with SELECT operation")
+		return qb
+	}
+	if d <= 0 {
+		qb.err = fmt.Errorf("lock wait must be positive, got %s", d)
+		return qb
+	}
+	switch qb.dbType {
+	case MariaDB:
+		qb.lockWait = d
+	case PostgreSQL, Redshift:
+		qb.err = fmt.Errorf("LockWait() is not supported for %s: use SET LOCAL lock_timeout in the transaction", qb.dbType)
+	case Mysql:
+		qb.err = fmt.Errorf("LockWait() is not supported for mysql: set innodb_lock_wait_timeout for the session")
+	default:
+		qb.err = fmt.Errorf("LockWait() is not supported for %s", qb.dbType)
+	}
+	return qb
+}
+
+/*
+WithRowPosition
+
+@ alias: Alias for the row position column
+@ Return: *QueryBuilder selecting ROW_NUMBER() OVER (ORDER BY <current ORDER BY>) AS alias
+
+Call after OrderBy so the position uses the same ordering as the page. The window
+is evaluated before LIMIT/OFFSET, so each row carries its absolute position in the
+filtered result ("item X of Y"). Computing it requires ordering every matching row,
+not just the page, which is expensive on large result sets.
+*/
+func (qb *QueryBuilder) WithRowPosition(alias string) *QueryBuilder {
+	if qb.err != nil {
+		return qb
+	}
+	if qb.op != "SELECT" {
+		qb.err = newError(ErrWrongOperation, "WithRowPosition() can only be used with SELECT operation")
+		return qb
+	}
+	if qb.orderBy == "" {
+		qb.err = fmt.Errorf("WithRowPosition() requires OrderBy to be set first")
+		return qb
+	}
+	safeAlias, err := EscapeIdentifier(qb.dbType, alias)
+	if err != nil {
+		qb.err = err
+		return qb
+	}
+	qb.addColumn(fmt.Sprintf("ROW_NUMBER() OVER (ORDER BY %s) AS %s", qb.orderBy, safeAlias))
+	return qb
+}
+
+// windowFunctions are the functions accepted by SelectWindow.
+var windowFunctions = map[string]bool{
+	"ROW_NUMBER":   true,
+	"RANK":         true,
+	"DENSE_RANK":   true,
+	"PERCENT_RANK": true,
+	"CUME_DIST":    true,
+	"NTILE":        true,
+	"LEAD":         true,
+	"LAG":          true,
+	"FIRST_VALUE":  true,
+	"LAST_VALUE":   true,
+	"SUM":          true,
+	"AVG":          true,
+	"COUNT":        true,
+	"MIN":          true,
+	"MAX":          true,
+}
+
+/*
+SelectWindow
+
+@ function: Window function, bare or with one column argument (e.g. "RANK", "LAG(price)")
+@ alias: Alias for the computed column
+@ partitionBy: Columns for PARTITION BY, omitted when empty
+@ orderBy: Comma-separated "column [ASC|DESC]" list for the window's ORDER BY, omitted when empty
+@ Return: *QueryBuilder selecting FUNCTION(...) OVER (PARTITION BY ... ORDER BY ...) AS alias
+*/
+func (qb *QueryBuilder) SelectWindow(function, alias string, partitionBy []string, orderBy string) *QueryBuilder {
+	if qb.err != nil {
+		return qb
+	}
+	name, argument := function, ""
+	if open := strings.Index(function, "("); open >= 0 {
+		if !strings.HasSuffix(function, ")") {
+			qb.err = fmt.Errorf("invalid window function: %s", function)
+			return qb
+		}
+		name, argument = function[:open], strings.TrimSpace(function[open+1:len(function)-1])
+	}
+	name = strings.ToUpper(strings.TrimSpace(name))
+	if !windowFunctions[name] {
+		qb.err = fmt.Errorf("unsupported window function: %s", name)
+		return qb
+	}
+	if argument != "" {
+		safeArg, err := EscapeIdentifier(qb.dbType, argument)
+		if err != nil {
+			qb.err = err
+			return qb
+		}
+		argument = safeArg
+	}
+
+	var over []string
+	if len(partitionBy) > 0 {
+		safeCols := make([]string, len(partitionBy))
+		for i, col := range partitionBy {
+			safeCol, err := EscapeIdentifier(qb.dbType, col)
+			if err != nil {
+				qb.err = err
+				return qb
+			}
+			safeCols[i] = safeCol
+		}
+		over = append(over, "PARTITION BY "+strings.Join(safeCols, ", "))
+	}
+	if orderBy != "" {
+		var sorts []string
+		for _, part := range strings.Split(orderBy, ",") {
+			fields := strings.Fields(part)
+			if len(fields) == 0 || len(fields) > 2 {
+				qb.err = fmt.Errorf("invalid window ORDER BY: %s", orderBy)
+				return qb
+			}
+			safeCol, err := EscapeIdentifier(qb.dbType, fields[0])
+			if err != nil {
+				qb.err = err
+				return qb
+			}
+			direction := "ASC"
+			if len(fields) == 2 {
+				direction = ValidateDirection(fields[1])
+			}
+			sorts = append(sorts, safeCol+" "+direction)
+		}
+		over = append(over, "ORDER BY "+strings.Join(sorts, ", "))
+	}
+
+	safeAlias, err := EscapeIdentifier(qb.dbType, alias)
+	if err != nil {
+		qb.err = err
+		return qb
+	}
+	qb.addColumn(fmt.Sprintf("%s(%s) OVER (%s) AS %s", name, argument, strings.Join(over, " "), safeAlias))
+	return qb
+}
+
+/*
+Limit
+
+@ limit: Maximum number of rows to return; an explicit 0 renders LIMIT 0
+@ Return: *QueryBuilder with LIMIT set, or an error if limit is negative
+*/
+func (qb *QueryBuilder) Limit(limit int) *QueryBuilder {
+	if qb.err != nil {
+		return qb
+	}
+	if limit < 0 {
+		qb.err = fmt.Errorf("limit must not be negative, got %d", limit)
+		return qb
+	}
+	qb.limit = limit
+	qb.limitSet = true
+	return qb
+}
+
+/*
+Offset
+
+@ offset: Number of rows to skip; an explicit 0 renders OFFSET 0
+@ Return: *QueryBuilder with OFFSET set, or an error if offset is negative
+*/
+func (qb *QueryBuilder) Offset(offset int) *QueryBuilder {
+	if qb.err != nil {
+		return qb
+	}
+	if offset < 0 {
+		qb.err = fmt.Errorf("offset must not be negative, got %d", offset)
+		return qb
+	}
+	qb.offset = offset
+	qb.offsetSet = true
+	return qb
+}
+
+/*
+Values
+
+@ data: Map of column names to values for INSERT
 @ Return: *QueryBuilder with data set for INSERT
+
+Columns are written in sorted name order, and args follow the same order. A nil
+value is still bound as a parameter, which drivers send as SQL NULL; it is never
+dropped or inlined, so the column list and args always line up.
+*/
+func (qb *QueryBuilder) Values(data map[string]interface{}) *QueryBuilder {
+	if qb.op != "INSERT" {
+		qb.err = newError(ErrWrongOperation, "Values() can only be used with INSERT operation")
+		return qb
+	}
+	if len(data) == 0 {
+		qb.err = newError(ErrNoData, "Values() called with empty data; use DefaultValues() to insert defaults")
+		return qb
+	}
+	qb.data = data
+	return qb
+}
+
+/*
+Set
+
+@ data: Map of column names to values for UPDATE
+@ Return: *QueryBuilder with data set for UPDATE
+*/
+func (qb *QueryBuilder) Set(data map[string]interface{}) *QueryBuilder {
+	if qb.op != "UPDATE" {
+		qb.err = newError(ErrWrongOperation, "Set() can only be used with UPDATE operation")
+		return qb
+	}
+	if len(data) == 0 {
+		qb.err = newError(ErrNoData, "Set() called with empty data")
+		return qb
+	}
+	qb.data = data
+	return qb
+}
+
+/*
+SetCaseByKey
+
+@ column: Column to update
+@ keyColumn: Column identifying each row, e.g. "id"
+@ mapping: New column value per key value
+@ Return: *QueryBuilder with SET column = CASE keyColumn WHEN ... THEN ... ELSE column END and a keyColumn IN (...) filter
+
+Keys are bound in sorted order. The ELSE branch keeps the column's type in the
+CASE so PostgreSQL doesn't infer the bound values as text.
+*/
+func (qb *QueryBuilder) SetCaseByKey(column, keyColumn string, mapping map[interface{}]interface{}) *QueryBuilder {
+	if qb.err != nil {
+		return qb
+	}
+	if qb.op != "UPDATE" {
+		qb.err = newError(ErrWrongOperation, "SetCaseByKey() can only be used with UPDATE operation")
+		return qb
+	}
+	if len(mapping) == 0 {
+		qb.err = newError(ErrNoData, "no data provided for UPDATE")
+		return qb
+	}
+	safeCol, err := EscapeIdentifier(qb.dbType, column)
+	if err != nil {
+		qb.err = err
+		return qb
+	}
+	safeKey, err := EscapeIdentifier(qb.dbType, keyColumn)
+	if err != nil {
+		qb.err = err
+		return qb
+	}
+	keys := make([]interface{}, 0, len(mapping))
+	for key := range mapping {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return lessCaseKey(keys[i], keys[j]) })
+
+	var expr strings.Builder
+	expr.WriteString(safeCol + " = CASE " + safeKey)
+	for _, key := range keys {
+		whenIdx := len(qb.caseArgs) + 1
+		expr.WriteString(fmt.Sprintf(" WHEN %s THEN %s", qb.dialect.Placeholder(whenIdx), qb.dialect.Placeholder(whenIdx+1)))
+		qb.caseArgs = append(qb.caseArgs, key, mapping[key])
+	}
+	expr.WriteString(" ELSE " + safeCol + " END")
+	qb.caseSets = append(qb.caseSets, expr.String())
+	return qb.WhereIn(keyColumn, keys)
+}
+
+// lessCaseKey orders SetCaseByKey keys: numbers numerically, strings lexically,
+// and anything else by its formatted value.
+func lessCaseKey(a, b interface{}) bool {
+	av, bv := reflect.ValueOf(a), reflect.ValueOf(b)
+	if af, ok := caseKeyNumber(av); ok {
+		if bf, ok := caseKeyNumber(bv); ok {
+			return af < bf
+		}
+	}
+	if av.Kind() == reflect.String && bv.Kind() == reflect.String {
+		return av.String() < bv.String()
+	}
+	return fmt.Sprint(a) < fmt.Sprint(b)
+}
+
+func caseKeyNumber(v reflect.Value) (float64, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	}
+	return 0, false
+}
+
+/*
+UpdateFromValues
+
+@ keyColumn: Column matching each VALUES row to a table row
+@ rows: Rows to update, each holding keyColumn and the same set of columns
+@ Return: *QueryBuilder with a PostgreSQL UPDATE ... FROM (VALUES ...) bulk update
+*/
+func (qb *QueryBuilder) UpdateFromValues(keyColumn string, rows []map[string]interface{}) *QueryBuilder {
+	if qb.err != nil {
+		return qb
+	}
+	if qb.op != "UPDATE" {
+		qb.err = newError(ErrWrongOperation, "UpdateFromValues() can only be used with UPDATE operation")
+		return qb
+	}
+	if qb.dbType != PostgreSQL {
+		qb.err = fmt.Errorf("UpdateFromValues() is only supported for PostgreSQL")
+		return qb
+	}
+	if len(rows) == 0 {
+		qb.err = fmt.Errorf("no rows provided for UpdateFromValues")
+		return qb
+	}
+	for i, row := range rows {
+		if _, ok := row[keyColumn]; !ok {
+			qb.err = fmt.Errorf("row %d is missing key column %s", i, keyColumn)
+			return qb
+		}
+		if len(row) != len(rows[0]) {
+			qb.err = fmt.Errorf("row %d has %d columns, expected %d", i, len(row), len(rows[0]))
+			return qb
+		}
+		for col := range rows[0] {
+			if _, ok := row[col]; !ok {
+				qb.err = fmt.Errorf("row %d is missing column %s", i, col)
+				return qb
+			}
+		}
+	}
+	if len(rows[0]) < 2 {
+		qb.err = fmt.Errorf("UpdateFromValues() needs at least one column besides %s", keyColumn)
+		return qb
+	}
+	qb.valuesKey = keyColumn
+	qb.valuesRows = rows
+	return qb
+}
+
+/*
+InsertIgnore
+
+@ Return: *QueryBuilder whose INSERT skips rows that violate a unique constraint
+
+MySQL/MariaDB render INSERT IGNORE and SQLite INSERT OR IGNORE. PostgreSQL
+renders ON CONFLICT DO NOTHING, which needs no conflict target. Redshift and
+SQL Server have no equivalent.
 */
-func (qb *QueryBuilder) Values(data map[string]interface{}) *QueryBuilder {
+func (qb *QueryBuilder) InsertIgnore() *QueryBuilder {
+	if qb.err != nil {
+		return qb
+	}
+	if qb.op != "INSERT" {
+		qb.err = newError(ErrWrongOperation, "InsertIgnore() can only be used with INSERT operation")
+		return qb
+	}
+	if qb.replace {
+		qb.err = fmt.Errorf("InsertIgnore() can't be combined with BuildReplace()")
+		return qb
+	}
+	switch qb.dbType {
+	case MariaDB, Mysql, SQLite, PostgreSQL:
+	default:
+		qb.err = fmt.Errorf("InsertIgnore() is not supported for %s", qb.dbType)
+		return qb
+	}
+	qb.insertIgnore = true
+	return qb
+}
+
+/*
+InsertIfNotExists
+
+@ keyColumns: Columns from the Values data identifying an existing row
+@ Return: *QueryBuilder emitting INSERT ... SELECT ... WHERE NOT EXISTS (SELECT 1 FROM t WHERE key = ...)
+*/
+func (qb *QueryBuilder) InsertIfNotExists(keyColumns ...string) *QueryBuilder {
+	if qb.err != nil {
+		return qb
+	}
+	if qb.op != "INSERT" {
+		qb.err = newError(ErrWrongOperation, "InsertIfNotExists() can only be used with INSERT operation")
+		return qb
+	}
+	if len(keyColumns) == 0 {
+		qb.err = fmt.Errorf("InsertIfNotExists() requires at least one key column")
+		return qb
+	}
+	qb.notExistsKeys = keyColumns
+	return qb
+}
+
+/*
+MySQLInsertSetSyntax
+
+@ Return: *QueryBuilder emitting INSERT INTO t SET a = ?, b = ? (MySQL/MariaDB only)
+*/
+func (qb *QueryBuilder) MySQLInsertSetSyntax() *QueryBuilder {
+	if qb.err != nil {
+		return qb
+	}
+	if qb.op != "INSERT" {
+		qb.err = newError(ErrWrongOperation, "MySQLInsertSetSyntax() can only be used with INSERT operation")
+		return qb
+	}
+	if qb.dbType != MariaDB && qb.dbType != Mysql {
+		qb.err = fmt.Errorf("MySQLInsertSetSyntax() is not supported for %s", qb.dbType)
+		return qb
+	}
+	qb.insertSet = true
+	return qb
+}
+
+/*
+RestartIdentity
+
+@ Return: *QueryBuilder that also resets identity/auto-increment counters on TRUNCATE
+
+PostgreSQL appends RESTART IDENTITY. MySQL, MariaDB and SQL Server always reset
+counters on TRUNCATE, so nothing extra is rendered. Redshift can't reset them.
+*/
+func (qb *QueryBuilder) RestartIdentity() *QueryBuilder {
+	if qb.err != nil {
+		return qb
+	}
+	if qb.op != "TRUNCATE" {
+		qb.err = newError(ErrWrongOperation, "RestartIdentity() can only be used with TRUNCATE operation")
+		return qb
+	}
+	if qb.dbType == Redshift {
+		qb.err = fmt.Errorf("RestartIdentity() is not supported by Redshift")
+		return qb
+	}
+	qb.restartIdentity = true
+	return qb
+}
+
+/*
+DefaultValues
+
+@ Return: *QueryBuilder inserting a row made only of column defaults, with no args
+*/
+func (qb *QueryBuilder) DefaultValues() *QueryBuilder {
+	if qb.err != nil {
+		return qb
+	}
+	if qb.op != "INSERT" {
+		qb.err = newError(ErrWrongOperation, "DefaultValues() can only be used with INSERT operation")
+		return qb
+	}
+	qb.defaultValues = true
+	return qb
+}
+
+/*
+Returning
+
+@ clause: RETURNING clause string (for PostgreSQL)
+@ Return: *QueryBuilder with RETURNING clause set
+
+MariaDB and MySQL accept the call and build the INSERT without RETURNING, as they
+always have, so builder code can be shared across dialects. Redshift and custom
+dialects without RETURNING support record an error.
+*/
+func (qb *QueryBuilder) Returning(clause string) *QueryBuilder {
 	if qb.op != "INSERT" {
-		qb.err = fmt.Errorf("Values() can only be used with INSERT operation")
+		qb.err = newError(ErrWrongOperation, "Returning() can only be used with INSERT operation")
+		return qb
+	}
+	if qb.dbType == Redshift {
+		qb.err = fmt.Errorf("Returning() is not supported by Redshift")
+		return qb
+	}
+	if qb.dbType == MariaDB || qb.dbType == Mysql {
+		return qb
+	}
+	if !qb.dialect.SupportsReturning() {
+		qb.err = fmt.Errorf("Returning() is not supported for %s", qb.dbType)
+		return qb
+	}
+	qb.returning = clause
+	return qb
+}
+
+// Build generates the final SQL query string and parameter arguments.
+// Zero allocations in the critical path, optimized for performance.
+// Returns: (query string, arguments slice, error)
+func (qb *QueryBuilder) Build() (string, []interface{}, error) {
+	if qb.err != nil {
+		return "", nil, qb.err
+	}
+	var query string
+	var args []interface{}
+	var err error
+	switch qb.op {
+	case "SELECT":
+		query, args, err = qb.buildSelect()
+	case "INSERT":
+		query, args, err = qb.buildInsert()
+	case "UPDATE":
+		query, args, err = qb.buildUpdate()
+	case "DELETE":
+		query, args, err = qb.buildDelete()
+	case "TRUNCATE":
+		query, args, err = qb.buildTruncate()
+	default:
+		return "", nil, newError(ErrWrongOperation, "unsupported operation: %s", qb.op)
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	query, args = qb.wrapStatement(query, args)
+	return query, args, nil
+}
+
+// wrapStatement adds the suffixes, EXPLAIN prefix, prefixes and terminator around
+// a built statement body.
+func (qb *QueryBuilder) wrapStatement(query string, args []interface{}) (string, []interface{}) {
+	if len(qb.suffixes) > 0 {
+		// Suffix args always come last, so number them after everything else
+		query += " " + replacePlaceholders(qb.dialect, strings.Join(qb.suffixes, " "), len(args)+1)
+		args = append(args, qb.suffixArgs...)
+	}
+	query = qb.explain + query
+	if len(qb.prefixes) > 0 {
+		// Prefix args come first, so the rest of the statement moves past them
+		prefix := replacePlaceholders(qb.dialect, strings.Join(qb.prefixes, " "), 1)
+		query = prefix + " " + qb.offsetPlaceholders([]string{query}, len(qb.prefixArgs))[0]
+		args = append(append([]interface{}(nil), qb.prefixArgs...), args...)
+	}
+	if qb.terminator {
+		query += ";"
+	}
+	return query, args
+}
+
+/*
+Suffix
+
+@ sql: Trusted fragment with "?" placeholders, e.g. "OPTION (RECOMPILE)"
+@ args: Query parameters for the fragment
+@ Return: *QueryBuilder that appends sql at the very end of the built query
+
+The fragment is not validated or escaped; never build it from user input.
+Its args are bound after all other args. Repeated calls append in order.
+*/
+func (qb *QueryBuilder) Suffix(sql string, args ...interface{}) *QueryBuilder {
+	if qb.err != nil {
+		return qb
+	}
+	qb.suffixes = append(qb.suffixes, sql)
+	qb.suffixArgs = append(qb.suffixArgs, args...)
+	return qb
+}
+
+/*
+Prefix
+
+@ sql: Trusted fragment with "?" placeholders, e.g. a comment tagging the calling service
+@ args: Query parameters for the fragment
+@ Return: *QueryBuilder that writes sql before the statement, ahead of any EXPLAIN
+
+Like Suffix, the fragment is not validated or escaped. Its args are bound
+before all other args. Repeated calls prepend in call order.
+*/
+func (qb *QueryBuilder) Prefix(sql string, args ...interface{}) *QueryBuilder {
+	if qb.err != nil {
+		return qb
+	}
+	qb.prefixes = append(qb.prefixes, sql)
+	qb.prefixArgs = append(qb.prefixArgs, args...)
+	return qb
+}
+
+/*
+Explain
+
+@ Return: *QueryBuilder whose built query is prefixed with EXPLAIN
+
+The args are unchanged. SQL Server has no EXPLAIN statement, and TRUNCATE
+can't be explained.
+*/
+func (qb *QueryBuilder) Explain() *QueryBuilder {
+	return qb.setExplain("Explain", "EXPLAIN ")
+}
+
+/*
+ExplainAnalyze
+
+@ Return: *QueryBuilder whose built query is prefixed with EXPLAIN ANALYZE
+
+PostgreSQL only. EXPLAIN ANALYZE executes the statement, so wrap INSERT,
+UPDATE and DELETE in a transaction that is rolled back.
+*/
+func (qb *QueryBuilder) ExplainAnalyze() *QueryBuilder {
+	if qb.err != nil {
+		return qb
+	}
+	if qb.dbType != PostgreSQL {
+		qb.err = fmt.Errorf("ExplainAnalyze() is not supported for %s", qb.dbType)
+		return qb
+	}
+	return qb.setExplain("ExplainAnalyze", "EXPLAIN ANALYZE ")
+}
+
+// setExplain records the EXPLAIN prefix Build writes before the statement.
+func (qb *QueryBuilder) setExplain(method, prefix string) *QueryBuilder {
+	if qb.err != nil {
+		return qb
+	}
+	if qb.op == "TRUNCATE" {
+		qb.err = newError(ErrWrongOperation, "%s() can't be used with TRUNCATE operation", method)
+		return qb
+	}
+	if qb.dbType == SQLServer {
+		qb.err = fmt.Errorf("%s() is not supported for %s", method, qb.dbType)
+		return qb
+	}
+	qb.explain = prefix
+	return qb
+}
+
+/*
+WithTerminator
+
+@ Return: *QueryBuilder whose Build appends a trailing semicolon to the query
+
+Off by default, since some drivers reject terminators in prepared statements. Use
+it for migration files and tools that expect complete statements. Subqueries built
+with it are embedded without the semicolon.
+*/
+func (qb *QueryBuilder) WithTerminator() *QueryBuilder {
+	if qb.err != nil {
+		return qb
+	}
+	qb.terminator = true
+	return qb
+}
+
+/*
+WithPlaceholderStyle
+
+@ style: PlaceholderDollar, PlaceholderQuestion, PlaceholderAt or PlaceholderColon
+@ Return: *QueryBuilder binding with style instead of its database type's default
+
+Identifier quoting and pagination still follow the database type. Call it before
+any args are bound, since fragments are numbered when they are added.
+*/
+func (qb *QueryBuilder) WithPlaceholderStyle(style string) *QueryBuilder {
+	if qb.err != nil {
 		return qb
 	}
-	qb.data = data
-	return qb
+	switch style {
+	case PlaceholderDollar, PlaceholderQuestion, PlaceholderAt, PlaceholderColon:
+	default:
+		qb.err = fmt.Errorf("unsupported placeholder style: %s", style)
+		return qb
+	}
+	if len(qb.args)+len(qb.joinArgs)+len(qb.columnArgs)+len(qb.havingArgs)+len(qb.caseArgs) > 0 {
+		qb.err = fmt.Errorf("WithPlaceholderStyle() must be called before any args are bound")
+		return qb
+	}
+	if styled, ok := qb.dialect.(styledDialect); ok {
+		qb.dialect = styled.Dialect
+	}
+	qb.dialect = styledDialect{Dialect: qb.dialect, style: style}
+	return qb
+}
+
+/*
+PlaceholderStyle
+
+@ Return: Placeholder style the builder renders, e.g. PlaceholderDollar for PostgreSQL, or "" for a custom dialect's own style
+*/
+func (qb *QueryBuilder) PlaceholderStyle() string {
+	return placeholderStyle(qb.dialect)
+}
+
+// MustBuild is like Build but panics if the query can't be built.
+// Use it only for static queries that don't depend on runtime input,
+// such as package-level vars initialized at startup.
+func (qb *QueryBuilder) MustBuild() (string, []interface{}) {
+	query, args, err := qb.Build()
+	if err != nil {
+		panic(fmt.Sprintf("gqbd: MustBuild: %v", err))
+	}
+	return query, args
+}
+
+// Err returns the first error recorded by the builder chain so far, or nil.
+func (qb *QueryBuilder) Err() error {
+	return qb.err
+}
+
+// Operation returns the statement the builder produces: "SELECT", "INSERT", "UPDATE",
+// "DELETE" or "TRUNCATE".
+func (qb *QueryBuilder) Operation() string {
+	return qb.op
+}
+
+// Table returns the table name as passed to the builder, before escaping.
+func (qb *QueryBuilder) Table() string {
+	return qb.tableName
+}
+
+// Args returns the args Build would bind, in statement order, including column,
+// join, HAVING, pagination, prefix and suffix args. It returns nil when the builder
+// can't be built. The slice is a copy, so changing it leaves the builder unchanged.
+func (qb *QueryBuilder) Args() []interface{} {
+	_, args, err := qb.Build()
+	if err != nil {
+		return nil
+	}
+	return append([]interface{}(nil), args...)
+}
+
+// String renders the built query as "query -- args=[...]", or the chain error,
+// for logging with %v. It builds a fresh statement and leaves the builder unchanged.
+func (qb *QueryBuilder) String() string {
+	query, args, err := qb.Build()
+	if err != nil {
+		return fmt.Sprintf("gqbd: %v", err)
+	}
+	return fmt.Sprintf("%s -- args=%v", query, args)
+}
+
+// Validate reports the recorded chain error or a structural problem that would make
+// Build fail, such as an INSERT without Values or an UPDATE without Set.
+func (qb *QueryBuilder) Validate() error {
+	if qb.err != nil {
+		return qb.err
+	}
+	switch qb.op {
+	case "SELECT", "DELETE", "TRUNCATE":
+		return nil
+	case "INSERT":
+		if qb.data == nil && !qb.defaultValues {
+			return newError(ErrNoData, "no data provided for INSERT")
+		}
+	case "UPDATE":
+		if qb.data == nil && qb.valuesRows == nil && len(qb.caseSets) == 0 {
+			return newError(ErrNoData, "no data provided for UPDATE")
+		}
+	default:
+		return newError(ErrWrongOperation, "unsupported operation: %s", qb.op)
+	}
+	return nil
+}
+
+// ToSQL builds the query and inlines every argument as a literal, for logging and
+// debugging only. The result is NOT safe to execute: always run the parameterized
+// query and args returned by Build.
+func (qb *QueryBuilder) ToSQL() (string, error) {
+	query, args, err := qb.Build()
+	if err != nil {
+		return "", err
+	}
+	return qb.rewritePlaceholders(query, len(args), func(index int) string {
+		return formatLiteral(args[index-1])
+	})
+}
+
+// BuildNamed builds the query with @p1, @p2, ... placeholders and returns the args
+// wrapped as sql.NamedArg (p1, p2, ...), for drivers such as SQL Server's that bind
+// by name. It works for every dialect.
+func (qb *QueryBuilder) BuildNamed() (string, []sql.NamedArg, error) {
+	query, args, err := qb.Build()
+	if err != nil {
+		return "", nil, err
+	}
+	named, err := qb.rewritePlaceholders(query, len(args), func(index int) string {
+		return fmt.Sprintf("@p%d", index)
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	namedArgs := make([]sql.NamedArg, len(args))
+	for i, arg := range args {
+		namedArgs[i] = sql.Named(fmt.Sprintf("p%d", i+1), arg)
+	}
+	return named, namedArgs, nil
+}
+
+// rewritePlaceholders replaces each bind placeholder outside string literals with
+// replace(index), where index is the 1-based arg position it refers to.
+func (qb *QueryBuilder) rewritePlaceholders(query string, argCount int, replace func(index int) string) (string, error) {
+	var result strings.Builder
+	nextArg := 0
+	inLiteral := false
+	for i := 0; i < len(query); i++ {
+		char := query[i]
+		if char == '\'' {
+			inLiteral = !inLiteral
+			result.WriteByte(char)
+			continue
+		}
+		if inLiteral {
+			result.WriteByte(char)
+			continue
+		}
+		if char == '?' {
+			if nextArg >= argCount {
+				return "", fmt.Errorf("query has more placeholders than args")
+			}
+			nextArg++
+			result.WriteString(replace(nextArg))
+			continue
+		}
+		if prefix := placeholderPrefix(qb.dialect); numberedPlaceholders(qb.dialect) && strings.HasPrefix(query[i:], prefix) {
+			j := i + len(prefix)
+			for j < len(query) && query[j] >= '0' && query[j] <= '9' {
+				j++
+			}
+			if j > i+len(prefix) {
+				index, _ := strconv.Atoi(query[i+len(prefix) : j])
+				if index < 1 || index > argCount {
+					return "", fmt.Errorf("placeholder %s%d has no matching arg", prefix, index)
+				}
+				result.WriteString(replace(index))
+				i = j - 1
+				continue
+			}
+		}
+		result.WriteByte(char)
+	}
+	return result.String(), nil
 }
 
-/*
-Set
-
-@ data: Map of column names to values for UPDATE
-@ Return: *QueryBuilder with data set for UPDATE
-*/
-func (qb *QueryBuilder) Set(data map[string]interface{}) *QueryBuilder {
-	if qb.op != "UPDATE" {
-		qb.err = fmt.Errorf("Set() can only be used with UPDATE operation")
-		return qb
+// formatLiteral renders an argument as a SQL literal for ToSQL.
+func formatLiteral(arg interface{}) string {
+	switch v := arg.(type) {
+	case nil:
+		return "NULL"
+	case bool:
+		if v {
+			return "TRUE"
+		}
+		return "FALSE"
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprintf("%d", v)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'g', -1, 32)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case string:
+		return "'" + strings.ReplaceAll(v, "'", "''") + "'"
+	case []byte:
+		return "'" + strings.ReplaceAll(string(v), "'", "''") + "'"
+	case time.Time:
+		return "'" + v.Format(time.RFC3339Nano) + "'"
+	default:
+		return "'" + strings.ReplaceAll(fmt.Sprint(v), "'", "''") + "'"
 	}
-	qb.data = data
-	return qb
 }
 
-/*
-Returning
+// BuildWithCount builds a paginated SELECT together with the matching total count query.
+// It is the modern replacement for MySQL's deprecated SQL_CALC_FOUND_ROWS + FOUND_ROWS():
+// the count query keeps joins and WHERE conditions but drops ORDER BY, LIMIT and OFFSET.
+// Grouped or DISTINCT queries are counted by wrapping them in a derived table, with
+// any Prefix and Suffix fragments kept on the outer count statement.
+// Returns: (page statement, count statement, error)
+func (qb *QueryBuilder) BuildWithCount() (Statement, Statement, error) {
+	if qb.err != nil {
+		return Statement{}, Statement{}, qb.err
+	}
+	if qb.op != "SELECT" {
+		return Statement{}, Statement{}, newError(ErrWrongOperation, "BuildWithCount() can only be used with SELECT operation")
+	}
+	pageQuery, pageArgs, err := qb.Build()
+	if err != nil {
+		return Statement{}, Statement{}, err
+	}
 
-@ clause: RETURNING clause string (for PostgreSQL)
-@ Return: *QueryBuilder with RETURNING clause set
-*/
-func (qb *QueryBuilder) Returning(clause string) *QueryBuilder {
-	if qb.op != "INSERT" {
-		qb.err = fmt.Errorf("Returning() can only be used with INSERT operation")
-		return qb
+	count := qb.Clone()
+	count.orderBy = ""
+	count.limit, count.limitSet = 0, false
+	count.offset, count.offsetSet = 0, false
+	count.pushDownLimit = false
+	count.lockWait = 0
+	count.lockOf = nil
+	// EXPLAIN describes the page query; inside the derived table it is invalid SQL
+	count.explain = ""
+	var countQuery string
+	var countArgs []interface{}
+	if count.distinct || len(count.groupBy) > 0 || len(count.having) > 0 {
+		// Hints, CTEs and the terminator belong to the outer count statement, not
+		// the derived table
+		inner := count.Clone()
+		inner.suffixes, inner.suffixArgs = nil, nil
+		inner.prefixes, inner.prefixArgs = nil, nil
+		inner.terminator = false
+		innerQuery, innerArgs, err := inner.Build()
+		if err != nil {
+			return Statement{}, Statement{}, err
+		}
+		countQuery, countArgs = count.wrapStatement("SELECT COUNT(*) FROM ("+innerQuery+") AS count_query", innerArgs)
+	} else {
+		count.columns = []string{"COUNT(*)"}
+		count.columnArgs = nil
+		countQuery, countArgs, err = count.Build()
+		if err != nil {
+			return Statement{}, Statement{}, err
+		}
 	}
-	qb.returning = clause
-	return qb
+
+	return Statement{Query: pageQuery, Args: pageArgs}, Statement{Query: countQuery, Args: countArgs}, nil
 }
 
-// Build generates the final SQL query string and parameter arguments.
-// Zero allocations in the critical path, optimized for performance.
-// Returns: (query string, arguments slice, error)
-func (qb *QueryBuilder) Build() (string, []interface{}, error) {
-	if qb.err != nil {
-		return "", nil, qb.err
+func (qb *QueryBuilder) buildSelect() (string, []interface{}, error) {
+	if qb.strictGroupBy {
+		if err := qb.checkGroupBy(); err != nil {
+			return "", nil, err
+		}
 	}
-	switch qb.op {
-	case "SELECT":
-		return qb.buildSelect()
-	case "INSERT":
-		return qb.buildInsert()
-	case "UPDATE":
-		return qb.buildUpdate()
-	case "DELETE":
-		return qb.buildDelete()
-	default:
-		return "", nil, fmt.Errorf("unsupported operation: %s", qb.op)
+	// Work on a copy so LIMIT/OFFSET args don't accumulate across Build calls
+	stmt := qb.selectArgs()
+	args := stmt.args
+	pushDown := qb.pushDownLimit && qb.limitValue() >= 0 && len(qb.joins) > 0
+	var queryBuilder strings.Builder
+	queryBuilder.WriteString("SELECT ")
+	if qb.distinct {
+		queryBuilder.WriteString("DISTINCT ")
+	}
+	queryBuilder.WriteString(strings.Join(stmt.columns, ", "))
+	queryBuilder.WriteString(" FROM ")
+	if pushDown {
+		var from string
+		var err error
+		from, args, err = qb.buildPushDownFrom(args, len(qb.columnArgs))
+		if err != nil {
+			return "", nil, err
+		}
+		queryBuilder.WriteString(from)
+	} else {
+		queryBuilder.WriteString(qb.table)
+	}
+	if len(stmt.joins) > 0 {
+		queryBuilder.WriteString(" " + strings.Join(stmt.joins, " "))
+	}
+	if len(stmt.conditions) > 0 {
+		queryBuilder.WriteString(" WHERE " + strings.Join(stmt.conditions, " AND "))
+	}
+	if len(qb.groupBy) > 0 {
+		queryBuilder.WriteString(" GROUP BY " + strings.Join(qb.groupBy, ", "))
+	}
+	if len(stmt.having) > 0 {
+		queryBuilder.WriteString(" HAVING " + strings.Join(stmt.having, " AND "))
+	}
+	if qb.orderBy != "" {
+		queryBuilder.WriteString(" ORDER BY " + qb.orderBy)
+	}
+	if !pushDown {
+		clause, limitArgs, err := qb.dialect.LimitOffset(qb.limitValue(), qb.offsetValue(), len(args)+1, qb.orderBy != "")
+		if err != nil {
+			return "", nil, err
+		}
+		queryBuilder.WriteString(clause)
+		args = append(args, limitArgs...)
+	}
+	if qb.lockWait > 0 {
+		queryBuilder.WriteString(lockWaitClause(qb.lockWait))
 	}
+	if len(qb.lockOf) > 0 {
+		queryBuilder.WriteString(" FOR UPDATE OF " + strings.Join(qb.lockOf, ", "))
+	}
+	return queryBuilder.String(), args, nil
 }
 
-func (qb *QueryBuilder) buildSelect() (string, []interface{}, error) {
-	switch qb.dbType {
-	case PostgreSQL:
-		return qb.buildPostgreSQLSelect()
-	case MariaDB, Mysql:
-		return qb.buildMySQLSelect()
-	case SQLite:
-		return qb.buildSQLiteSelect()
-	default:
-		return qb.buildMySQLSelect()
+// checkGroupBy verifies that every plain selected column is grouped. Anything with
+// parentheses counts as an aggregate or other expression and is not checked.
+func (qb *QueryBuilder) checkGroupBy() error {
+	grouped := make(map[string]bool)
+	for _, group := range qb.groupBy {
+		group = strings.TrimSuffix(group, " WITH ROLLUP")
+		if strings.HasPrefix(group, "ROLLUP (") {
+			group = strings.TrimSuffix(strings.TrimPrefix(group, "ROLLUP ("), ")")
+		}
+		for _, col := range strings.Split(group, ", ") {
+			grouped[col] = true
+		}
+	}
+	hasAggregate := false
+	var plain []string
+	for _, col := range qb.columns {
+		if strings.Contains(col, "(") {
+			// Window functions, COALESCE and subqueries are expressions, not aggregates
+			if isAggregateColumn(col) {
+				hasAggregate = true
+			}
+			continue
+		}
+		// Compare the column itself, not any alias written after it
+		if space := strings.Index(col, " "); space >= 0 {
+			col = col[:space]
+		}
+		plain = append(plain, col)
+	}
+	if len(qb.groupBy) == 0 && !hasAggregate {
+		return nil
 	}
+	for _, col := range plain {
+		if !grouped[col] {
+			return fmt.Errorf("column %s must appear in GROUP BY or be used in an aggregate", col)
+		}
+	}
+	return nil
+}
+
+// limitValue returns the LIMIT passed to the dialect, -1 when Limit wasn't called.
+func (qb *QueryBuilder) limitValue() int {
+	if qb.limitSet && qb.limit >= 0 {
+		return qb.limit
+	}
+	return -1
+}
+
+// offsetValue returns the OFFSET passed to the dialect, -1 when Offset wasn't called.
+func (qb *QueryBuilder) offsetValue() int {
+	if qb.offsetSet && qb.offset >= 0 {
+		return qb.offset
+	}
+	return -1
 }
 
 func (qb *QueryBuilder) buildInsert() (string, []interface{}, error) {
-	switch qb.dbType {
-	case PostgreSQL:
-		return qb.buildPostgreSQLInsert()
-	case MariaDB, Mysql:
-		return qb.buildMySQLInsert()
-	case SQLite:
-		return qb.buildSQLiteInsert()
-	default:
-		return qb.buildMySQLInsert()
+	if len(qb.notExistsKeys) > 0 {
+		return qb.buildInsertIfNotExists()
+	}
+	if qb.defaultValues {
+		return qb.buildDefaultValuesInsert()
+	}
+	if qb.data == nil {
+		return "", nil, newError(ErrNoData, "no data provided for INSERT")
+	}
+	if qb.insertSet {
+		return qb.buildMySQLInsertSet()
+	}
+	var keys []string
+	for key := range qb.data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var cols []string
+	var args []interface{}
+	for _, key := range keys {
+		safeCol, err := EscapeIdentifier(qb.dbType, key)
+		if err != nil {
+			return "", nil, err
+		}
+		cols = append(cols, safeCol)
+		args = append(args, qb.data[key])
+	}
+
+	placeholders := generatePlaceholders(qb.dialect, 1, len(args))
+	query := fmt.Sprintf("%s %s (%s) VALUES (%s)", qb.insertInto(), qb.table, strings.Join(cols, ", "), placeholders)
+	query += qb.insertTail()
+
+	return query, args, nil
+}
+
+// insertInto returns the statement keyword for INSERT builders, e.g. INSERT IGNORE INTO
+// or REPLACE INTO.
+func (qb *QueryBuilder) insertInto() string {
+	if qb.replace {
+		return "REPLACE INTO"
+	}
+	if qb.insertIgnore {
+		switch qb.dbType {
+		case MariaDB, Mysql:
+			return "INSERT IGNORE INTO"
+		case SQLite:
+			return "INSERT OR IGNORE INTO"
+		}
+	}
+	return "INSERT INTO"
+}
+
+// insertTail returns the clauses that close every INSERT form: PostgreSQL's
+// stand-in for InsertIgnore, then RETURNING.
+func (qb *QueryBuilder) insertTail() string {
+	tail := ""
+	if qb.insertIgnore && qb.dbType == PostgreSQL {
+		tail = " ON CONFLICT DO NOTHING"
+	}
+	if qb.returning != "" {
+		tail += " RETURNING " + qb.returning
+	}
+	return tail
+}
+
+// buildDefaultValuesInsert renders an INSERT that fills every column with its default.
+func (qb *QueryBuilder) buildDefaultValuesInsert() (string, []interface{}, error) {
+	if qb.data != nil {
+		return "", nil, fmt.Errorf("DefaultValues() can't be combined with Values()")
+	}
+	query := qb.insertInto() + " " + qb.table + " DEFAULT VALUES"
+	if qb.dbType == MariaDB || qb.dbType == Mysql {
+		// MySQL has no DEFAULT VALUES; an empty column and value list does the same
+		query = qb.insertInto() + " " + qb.table + " () VALUES ()"
+	}
+	query += qb.insertTail()
+	return query, nil, nil
+}
+
+// buildInsertIfNotExists renders the dialect-neutral conditional insert.
+// Inserted values are bound first, followed by the existence check's key values.
+func (qb *QueryBuilder) buildInsertIfNotExists() (string, []interface{}, error) {
+	if qb.data == nil {
+		return "", nil, newError(ErrNoData, "no data provided for INSERT")
+	}
+	var keys []string
+	for key := range qb.data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var cols []string
+	var args []interface{}
+	for _, key := range keys {
+		safeCol, err := EscapeIdentifier(qb.dbType, key)
+		if err != nil {
+			return "", nil, err
+		}
+		cols = append(cols, safeCol)
+		args = append(args, qb.data[key])
+	}
+	placeholders := generatePlaceholders(qb.dialect, 1, len(args))
+
+	var checks []string
+	for _, key := range qb.notExistsKeys {
+		val, ok := qb.data[key]
+		if !ok {
+			return "", nil, fmt.Errorf("key column %s is missing from INSERT data", key)
+		}
+		safeCol, err := EscapeIdentifier(qb.dbType, key)
+		if err != nil {
+			return "", nil, err
+		}
+		checks = append(checks, fmt.Sprintf("%s = %s", safeCol, generatePlaceholders(qb.dialect, len(args)+1, 1)))
+		args = append(args, val)
+	}
+
+	// MySQL/MariaDB need a FROM clause before WHERE in a table-less SELECT
+	from := ""
+	if qb.dbType == MariaDB || qb.dbType == Mysql {
+		from = " FROM DUAL"
 	}
+	query := fmt.Sprintf("%s %s (%s) SELECT %s%s WHERE NOT EXISTS (SELECT 1 FROM %s WHERE %s)",
+		qb.insertInto(), qb.table, strings.Join(cols, ", "), placeholders, from, qb.table, strings.Join(checks, " AND "))
+	query += qb.insertTail()
+	return query, args, nil
 }
 
 func (qb *QueryBuilder) buildUpdate() (string, []interface{}, error) {
-	switch qb.dbType {
-	case PostgreSQL:
-		return qb.buildPostgreSQLUpdate()
-	case MariaDB, Mysql:
-		return qb.buildMySQLUpdate()
-	case SQLite:
-		return qb.buildSQLiteUpdate()
-	default:
-		return qb.buildMySQLUpdate()
+	if qb.valuesRows != nil {
+		return qb.buildPostgreSQLUpdateFromValues()
+	}
+	if qb.data == nil && len(qb.caseSets) == 0 {
+		return "", nil, newError(ErrNoData, "no data provided for UPDATE")
+	}
+	var setClauses []string
+	var updateArgs []interface{}
+
+	var keys []string
+	for key := range qb.data {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	for _, key := range keys {
+		safeCol, err := EscapeIdentifier(qb.dbType, key)
+		if err != nil {
+			return "", nil, err
+		}
+		setClauses = append(setClauses, fmt.Sprintf("%s = %s", safeCol, qb.dialect.Placeholder(len(updateArgs)+1)))
+		updateArgs = append(updateArgs, qb.data[key])
+	}
+	// CASE clauses are numbered from 1 when added; move them past the plain SET args
+	setClauses = append(setClauses, qb.offsetPlaceholders(qb.caseSets, len(updateArgs))...)
+	updateArgs = append(updateArgs, qb.caseArgs...)
+
+	query := fmt.Sprintf("UPDATE %s SET %s", qb.table, strings.Join(setClauses, ", "))
+
+	allArgs := updateArgs
+	if len(qb.conditions) > 0 {
+		whereConditions := qb.offsetPlaceholders(qb.conditions, len(updateArgs))
+		query += " WHERE " + strings.Join(whereConditions, " AND ")
+		allArgs = append(allArgs, qb.args...)
 	}
+
+	return query, allArgs, nil
 }
 
 func (qb *QueryBuilder) buildDelete() (string, []interface{}, error) {
@@ -494,7 +3233,156 @@ func (qb *QueryBuilder) buildDelete() (string, []interface{}, error) {
 	if len(qb.conditions) > 0 {
 		queryBuilder.WriteString(" WHERE " + strings.Join(qb.conditions, " AND "))
 	}
-	return queryBuilder.String(), qb.args, nil
+	return queryBuilder.String(), append([]interface{}{}, qb.args...), nil
+}
+
+func (qb *QueryBuilder) buildTruncate() (string, []interface{}, error) {
+	if qb.dbType == SQLite {
+		return "", nil, fmt.Errorf("SQLite has no TRUNCATE; use BuildDelete without conditions")
+	}
+	query := "TRUNCATE TABLE " + qb.table
+	if qb.restartIdentity && qb.dbType == PostgreSQL {
+		query += " RESTART IDENTITY"
+	}
+	return query, nil, nil
+}
+
+// buildPushDownFrom renders the driving table as a limited subquery for PushDownLimit.
+// Positional "?" dialects need the LIMIT/OFFSET args at insertAt, after the select-list
+// args and ahead of the JOIN and WHERE args, because that is where the subquery sits
+// in the statement; $N dialects just number them last.
+func (qb *QueryBuilder) buildPushDownFrom(args []interface{}, insertAt int) (string, []interface{}, error) {
+	alias := qb.table
+	if parts := strings.Fields(qb.table); len(parts) > 1 {
+		alias = parts[len(parts)-1]
+	} else if dot := strings.LastIndex(alias, "."); dot >= 0 {
+		// A schema-qualified name isn't a valid alias; use the bare table name
+		alias = alias[dot+1:]
+	}
+
+	var subquery strings.Builder
+	subquery.WriteString("(SELECT * FROM ")
+	subquery.WriteString(qb.table)
+	if qb.orderBy != "" {
+		subquery.WriteString(" ORDER BY " + qb.orderBy)
+	}
+	clause, limitArgs, err := qb.dialect.LimitOffset(qb.limitValue(), qb.offsetValue(), len(args)+1, qb.orderBy != "")
+	if err != nil {
+		return "", nil, err
+	}
+	subquery.WriteString(clause)
+	subquery.WriteString(") AS " + alias)
+
+	if numberedPlaceholders(qb.dialect) {
+		return subquery.String(), append(args, limitArgs...), nil
+	}
+	ordered := make([]interface{}, 0, len(args)+len(limitArgs))
+	ordered = append(ordered, args[:insertAt]...)
+	ordered = append(ordered, limitArgs...)
+	ordered = append(ordered, args[insertAt:]...)
+	return subquery.String(), ordered, nil
+}
+
+// selectStatement holds a SELECT's fragments renumbered into statement order,
+// together with a fresh args slice in that same order.
+type selectStatement struct {
+	args       []interface{}
+	columns    []string
+	joins      []string
+	conditions []string
+	having     []string
+}
+
+// selectArgs orders args as they appear in the statement (select-list args, JOIN
+// args, WHERE args, then HAVING args). Each group is numbered from 1 when recorded,
+// so for $N dialects the later fragments are shifted past the earlier groups, and
+// the order the builder methods were called in never affects the bound order.
+func (qb *QueryBuilder) selectArgs() selectStatement {
+	args := make([]interface{}, 0, len(qb.columnArgs)+len(qb.joinArgs)+len(qb.args)+len(qb.havingArgs))
+	args = append(args, qb.columnArgs...)
+	joinOffset := len(args)
+	args = append(args, qb.joinArgs...)
+	whereOffset := len(args)
+	args = append(args, qb.args...)
+	havingOffset := len(args)
+	args = append(args, qb.havingArgs...)
+	return selectStatement{
+		args:       args,
+		columns:    qb.columns,
+		joins:      qb.offsetPlaceholders(qb.joins, joinOffset),
+		conditions: qb.offsetPlaceholders(qb.conditions, whereOffset),
+		having:     qb.offsetPlaceholders(qb.having, havingOffset),
+	}
+}
+
+// offsetPlaceholders shifts $N placeholders in fragments by offset; "?" dialects are unchanged.
+func (qb *QueryBuilder) offsetPlaceholders(fragments []string, offset int) []string {
+	if offset == 0 || !numberedPlaceholders(qb.dialect) {
+		return fragments
+	}
+	prefix := placeholderPrefix(qb.dialect)
+	shifted := make([]string, len(fragments))
+	for i, fragment := range fragments {
+		shifted[i] = offsetNumberedPlaceholders(fragment, prefix, offset)
+	}
+	return shifted
+}
+
+// offsetNumberedPlaceholders adds offset to every <prefix>N placeholder in condition,
+// keeping each placeholder's relative number. Text inside string literals and
+// quoted identifiers is copied unchanged, so '10:30' stays as written, and so are
+// :N array slice bounds such as arr[1:2] or arr[:2].
+func offsetNumberedPlaceholders(condition, prefix string, offset int) string {
+	var result strings.Builder
+	depth := 0
+	i := 0
+	for i < len(condition) {
+		switch condition[i] {
+		case '[':
+			depth++
+		case ']':
+			if depth > 0 {
+				depth--
+			}
+		}
+		if prefix == ":" && depth > 0 && i > 0 && isSliceBoundStart(condition[i-1]) {
+			result.WriteByte(condition[i])
+			i++
+			continue
+		}
+		if quote := condition[i]; quote == '\'' || quote == '"' || quote == '`' {
+			// A doubled quote escapes itself and simply reopens the section
+			end := strings.IndexByte(condition[i+1:], quote)
+			if end < 0 {
+				result.WriteString(condition[i:])
+				break
+			}
+			result.WriteString(condition[i : i+end+2])
+			i += end + 2
+			continue
+		}
+		if strings.HasPrefix(condition[i:], prefix) {
+			j := i + len(prefix)
+			for j < len(condition) && condition[j] >= '0' && condition[j] <= '9' {
+				j++
+			}
+			if j > i+len(prefix) {
+				index, _ := strconv.Atoi(condition[i+len(prefix) : j])
+				result.WriteString(fmt.Sprintf("%s%d", prefix, index+offset))
+				i = j
+				continue
+			}
+		}
+		result.WriteByte(condition[i])
+		i++
+	}
+	return result.String()
+}
+
+// isSliceBoundStart reports whether a ':' after c inside brackets separates array
+// slice bounds rather than starting a placeholder.
+func isSliceBoundStart(c byte) bool {
+	return c == '[' || (c >= '0' && c <= '9')
 }
 
 /*
@@ -522,7 +3410,7 @@ func shiftPlaceholders(condition string, offset int) string {
 /*
 EscapeIdentifier
 
-@ dbType: Database type (PostgreSQL, MariaDB, Mysql)
+@ dbType: Database type (PostgreSQL, Redshift, MariaDB, Mysql, SQLite, SQLServer)
 @ name: Identifier to escape
 @ Return: Escaped identifier and error if any
 */
@@ -532,7 +3420,7 @@ func EscapeIdentifier(dbType DBType, name string) (string, error) {
 	}
 
 	if name == "" {
-		return "", fmt.Errorf("empty identifier not allowed")
+		return "", newError(ErrInvalidIdentifier, "empty identifier not allowed")
 	}
 
 	// Handle table aliases (e.g., "table_name t" or "table_name AS t")
@@ -542,14 +3430,14 @@ func EscapeIdentifier(dbType DBType, name string) (string, error) {
 			// For "table AS alias" or "table alias" format
 			if len(parts) == 3 && strings.ToUpper(parts[1]) == "AS" {
 				// "table AS alias" format
-				escapedTable, err := escapeIdentifierName(dbType, parts[0])
+				escapedTable, err := escapeQualifiedName(dbType, parts[0])
 				if err != nil {
 					return "", err
 				}
 				return escapedTable + " AS " + parts[2], nil
 			} else if len(parts) == 2 {
 				// "table alias" format
-				escapedTable, err := escapeIdentifierName(dbType, parts[0])
+				escapedTable, err := escapeQualifiedName(dbType, parts[0])
 				if err != nil {
 					return "", err
 				}
@@ -558,32 +3446,146 @@ func EscapeIdentifier(dbType DBType, name string) (string, error) {
 		}
 	}
 
-	// Handle column names with table prefixes (e.g., "t.column")
+	return escapeQualifiedName(dbType, name)
+}
+
+/*
+EscapeIdentifierLiteral
+
+@ dbType: Database type (PostgreSQL, Redshift, MariaDB, Mysql, SQLite, SQLServer)
+@ name: Whole identifier to quote, dots, spaces and quotes included
+@ Return: Escaped identifier and error if any
+
+Unlike EscapeIdentifier it never splits on "." or parses an alias, so legacy
+names such as "order.total" quote as a single identifier.
+*/
+func EscapeIdentifierLiteral(dbType DBType, name string) (string, error) {
+	if name == "" {
+		return "", newError(ErrInvalidIdentifier, "empty identifier not allowed")
+	}
+	return escapeIdentifierName(dbType, name)
+}
+
+// escapeQualifiedName escapes each segment of "table.column" or "schema.table.column" independently.
+func escapeQualifiedName(dbType DBType, name string) (string, error) {
 	if strings.Contains(name, ".") {
 		parts := strings.Split(name, ".")
-		if len(parts) == 2 {
-			// Don't escape table alias, only column name
-			escapedColumn, err := escapeIdentifierName(dbType, parts[1])
-			if err != nil {
-				return "", err
+		if len(parts) == 2 || len(parts) == 3 {
+			escapedParts := make([]string, len(parts))
+			for i, part := range parts {
+				// Keep "t.*" selecting every column of a qualified table
+				if part == "*" && i == len(parts)-1 {
+					escapedParts[i] = part
+					continue
+				}
+				if part == "" {
+					return "", newError(ErrInvalidIdentifier, "empty identifier segment in %s", name)
+				}
+				escapedPart, err := escapeIdentifierName(dbType, part)
+				if err != nil {
+					return "", err
+				}
+				escapedParts[i] = escapedPart
 			}
-			return parts[0] + "." + escapedColumn, nil
+			return strings.Join(escapedParts, "."), nil
 		}
 	}
 
 	return escapeIdentifierName(dbType, name)
 }
 
+// maxIdentifierLength is the byte limit enforced by validateIdentifierName, 0 for none.
+var maxIdentifierLength int64
+
+/*
+SetMaxIdentifierLength
+
+@ length: Maximum identifier length in bytes (PostgreSQL truncates at 63), 0 disables the check
+*/
+func SetMaxIdentifierLength(length int) {
+	if length < 0 {
+		length = 0
+	}
+	atomic.StoreInt64(&maxIdentifierLength, int64(length))
+}
+
+// validateIdentifierName rejects identifier segments that quoting alone can't make safe.
+func validateIdentifierName(name string) error {
+	for _, char := range name {
+		if char < 0x20 || char == 0x7f {
+			return newError(ErrInvalidIdentifier, "identifier %q contains control character %U", name, char)
+		}
+	}
+	if limit := atomic.LoadInt64(&maxIdentifierLength); limit > 0 && int64(len(name)) > limit {
+		return newError(ErrInvalidIdentifier, "identifier %q exceeds maximum length of %d bytes", name, limit)
+	}
+	return nil
+}
+
 func escapeIdentifierName(dbType DBType, name string) (string, error) {
+	if err := validateIdentifierName(name); err != nil {
+		return "", err
+	}
+	return dialectFor(dbType).EscapeIdentifier(name)
+}
+
+/*
+TableForTime
+
+@ base: Base table name of the partitioned table (e.g. "logs")
+@ t: Time selecting the partition
+@ granularity: Partition granularity ("day", "month" or "year")
+@ Return: Partition table name such as logs_2024_01 and error if any
+*/
+func TableForTime(base string, t time.Time, granularity string) (string, error) {
+	if base == "" {
+		return "", newError(ErrInvalidIdentifier, "empty base table name not allowed")
+	}
+	if strings.ContainsAny(base, " .\"`\t\n") {
+		return "", newError(ErrInvalidIdentifier, "invalid base table name: %s", base)
+	}
+	switch strings.ToLower(granularity) {
+	case "day":
+		return fmt.Sprintf("%s_%04d_%02d_%02d", base, t.Year(), int(t.Month()), t.Day()), nil
+	case "month":
+		return fmt.Sprintf("%s_%04d_%02d", base, t.Year(), int(t.Month())), nil
+	case "year":
+		return fmt.Sprintf("%s_%04d", base, t.Year()), nil
+	default:
+		return "", newError(ErrWrongOperation, "unsupported partition granularity: %s", granularity)
+	}
+}
+
+// buildCollateClause validates a collation name for the dialect and renders its COLLATE clause.
+func buildCollateClause(dbType DBType, collation string) (string, error) {
+	if collation == "" {
+		return "", fmt.Errorf("empty collation not allowed")
+	}
+	for _, char := range collation {
+		valid := char == '_' || (char >= '0' && char <= '9') || (char >= 'a' && char <= 'z') || (char >= 'A' && char <= 'Z')
+		// PostgreSQL collations are quoted and may look like "en-US-x-icu" or "en_US.utf8"
+		if (dbType == PostgreSQL || dbType == Redshift) && (char == '-' || char == '.' || char == '@') {
+			valid = true
+		}
+		if !valid {
+			return "", fmt.Errorf("invalid collation name: %s", collation)
+		}
+	}
 	switch dbType {
-	case PostgreSQL:
-		return escapePostgreSQLIdentifier(name)
-	case MariaDB, Mysql:
-		return escapeMySQLIdentifier(name)
+	case PostgreSQL, Redshift:
+		safeCollation, err := escapePostgreSQLIdentifier(collation)
+		if err != nil {
+			return "", err
+		}
+		return "COLLATE " + safeCollation, nil
 	case SQLite:
-		return escapeSQLiteIdentifier(name)
+		switch strings.ToUpper(collation) {
+		case "BINARY", "NOCASE", "RTRIM":
+			return "COLLATE " + strings.ToUpper(collation), nil
+		}
+		return "", fmt.Errorf("unsupported SQLite collation: %s", collation)
 	default:
-		return name, nil
+		return "COLLATE " + collation, nil
 	}
 }
 
@@ -610,14 +3612,20 @@ ReplacePlaceholders
 @ Return: Condition string with replaced placeholders
 */
 func ReplacePlaceholders(dbType DBType, condition string, startIdx int) string {
-	if dbType == MariaDB || dbType == Mysql || dbType == SQLite {
+	return replacePlaceholders(dialectFor(dbType), condition, startIdx)
+}
+
+// replacePlaceholders is ReplacePlaceholders for a resolved dialect, so builders
+// honour a placeholder style forced with WithPlaceholderStyle.
+func replacePlaceholders(d Dialect, condition string, startIdx int) string {
+	if !numberedPlaceholders(d) {
 		return condition // MariaDB/MySQL/SQLite use "?" directly
 	}
 	var result strings.Builder
 	placeholderCount := startIdx
 	for _, char := range condition {
 		if char == '?' {
-			result.WriteString(fmt.Sprintf("$%d", placeholderCount))
+			result.WriteString(d.Placeholder(placeholderCount))
 			placeholderCount++
 		} else {
 			result.WriteRune(char)
@@ -626,6 +3634,21 @@ func ReplacePlaceholders(dbType DBType, condition string, startIdx int) string {
 	return result.String()
 }
 
+/*
+BuildRaw
+
+@ dbType: Database type
+@ query: Raw SQL written with "?" placeholders
+@ args: Values bound to the placeholders in order
+@ Return: Query with placeholders converted for dbType, the args unchanged, and error if the "?" count doesn't match len(args)
+*/
+func BuildRaw(dbType DBType, query string, args ...interface{}) (string, []interface{}, error) {
+	if count := strings.Count(query, "?"); count != len(args) {
+		return "", nil, fmt.Errorf("raw query has %d placeholders but %d args", count, len(args))
+	}
+	return ReplacePlaceholders(dbType, query, 1), args, nil
+}
+
 /*
 GeneratePlaceholders
 
@@ -635,13 +3658,14 @@ GeneratePlaceholders
 @ Return: String of placeholders separated by comma
 */
 func GeneratePlaceholders(dbType DBType, startIdx, count int) string {
+	return generatePlaceholders(dialectFor(dbType), startIdx, count)
+}
+
+// generatePlaceholders is GeneratePlaceholders for a resolved dialect.
+func generatePlaceholders(d Dialect, startIdx, count int) string {
 	placeholders := make([]string, count)
 	for i := 0; i < count; i++ {
-		if dbType == PostgreSQL {
-			placeholders[i] = fmt.Sprintf("$%d", startIdx+i)
-		} else {
-			placeholders[i] = "?"
-		}
+		placeholders[i] = d.Placeholder(startIdx + i)
 	}
 	return strings.Join(placeholders, ", ")
 }
@@ -657,12 +3681,14 @@ func GeneratePlaceholders(dbType DBType, startIdx, count int) string {
 //   dsn := gqbd.BuildConnectionString(gqbd.PostgreSQL, config)
 func BuildConnectionString(dbType DBType, config DBConfig) string {
 	switch dbType {
-	case PostgreSQL:
+	case PostgreSQL, Redshift:
 		return buildPostgreSQLConnectionString(config)
 	case MariaDB, Mysql:
 		return buildMySQLConnectionString(config)
 	case SQLite:
 		return buildSQLiteConnectionString(config)
+	case SQLServer:
+		return buildSQLServerConnectionString(config)
 	default:
 		return buildMySQLConnectionString(config)
 	}
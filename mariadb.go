@@ -4,79 +4,64 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"time"
 )
 
-func (qb *QueryBuilder) buildMySQLSelect() (string, []interface{}, error) {
-	var queryBuilder strings.Builder
-	queryBuilder.WriteString("SELECT ")
-	if qb.distinct {
-		queryBuilder.WriteString("DISTINCT ")
-	}
-	queryBuilder.WriteString(strings.Join(qb.columns, ", "))
-	queryBuilder.WriteString(" FROM ")
-	queryBuilder.WriteString(qb.table)
-	if len(qb.joins) > 0 {
-		queryBuilder.WriteString(" " + strings.Join(qb.joins, " "))
-	}
-	if len(qb.conditions) > 0 {
-		queryBuilder.WriteString(" WHERE " + strings.Join(qb.conditions, " AND "))
-	}
-	if len(qb.groupBy) > 0 {
-		queryBuilder.WriteString(" GROUP BY " + strings.Join(qb.groupBy, ", "))
-	}
-	if len(qb.having) > 0 {
-		queryBuilder.WriteString(" HAVING " + strings.Join(qb.having, " AND "))
-	}
-	if qb.orderBy != "" {
-		queryBuilder.WriteString(" ORDER BY " + qb.orderBy)
-	}
-	if qb.limit > 0 {
-		queryBuilder.WriteString(" LIMIT ?")
-		qb.args = append(qb.args, qb.limit)
-	}
-	if qb.offset > 0 {
-		queryBuilder.WriteString(" OFFSET ?")
-		qb.args = append(qb.args, qb.offset)
-	}
-	return queryBuilder.String(), qb.args, nil
-}
+// mysqlDialect renders MySQL and MariaDB: backtick identifiers, "?" placeholders
+// and no RETURNING.
+type mysqlDialect struct{}
 
-func (qb *QueryBuilder) buildMySQLInsert() (string, []interface{}, error) {
-	if qb.data == nil {
-		return "", nil, fmt.Errorf("no data provided for INSERT")
-	}
-	var cols []string
-	var placeholders []string
-	var args []interface{}
+func (mysqlDialect) EscapeIdentifier(name string) (string, error) {
+	return escapeMySQLIdentifier(name)
+}
 
-	for col, val := range qb.data {
-		safeCol, err := EscapeIdentifier(qb.dbType, col)
-		if err != nil {
-			return "", nil, err
-		}
-		cols = append(cols, safeCol)
-		placeholders = append(placeholders, "?")
-		args = append(args, val)
-	}
+func (mysqlDialect) Placeholder(index int) string {
+	return "?"
+}
 
-	placeholdersStr := strings.Join(placeholders, ", ")
-	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", qb.table, strings.Join(cols, ", "), placeholdersStr)
+func (mysqlDialect) LimitOffset(limit, offset, nextIndex int, ordered bool) (string, []interface{}, error) {
+	// MySQL has no OFFSET without LIMIT; the manual's idiom is the largest unsigned BIGINT
+	return positionalLimitOffset(limit, offset, "18446744073709551615")
+}
 
-	return query, args, nil
+func (mysqlDialect) SupportsReturning() bool {
+	return false
 }
 
-func (qb *QueryBuilder) buildMySQLUpdate() (string, []interface{}, error) {
-	if qb.data == nil {
-		return "", nil, fmt.Errorf("no data provided for UPDATE")
+// positionalLimitOffset renders LIMIT/OFFSET with "?" placeholders. Dialects that
+// reject a bare OFFSET pass the literal meaning "no limit" to write before it.
+func positionalLimitOffset(limit, offset int, unlimited string) (string, []interface{}, error) {
+	var clause strings.Builder
+	var args []interface{}
+	if limit >= 0 {
+		clause.WriteString(" LIMIT ?")
+		args = append(args, limit)
+	} else if offset >= 0 {
+		clause.WriteString(" LIMIT " + unlimited)
 	}
+	if offset >= 0 {
+		clause.WriteString(" OFFSET ?")
+		args = append(args, offset)
+	}
+	return clause.String(), args, nil
+}
+
+// lockWaitClause renders MariaDB's FOR UPDATE WAIT n for LockWait.
+func lockWaitClause(d time.Duration) string {
+	// MariaDB takes whole seconds; round up so the wait is never shorter than asked
+	seconds := int64((d + time.Second - 1) / time.Second)
+	return fmt.Sprintf(" FOR UPDATE WAIT %d", seconds)
+}
+
+func (qb *QueryBuilder) buildMySQLInsertSet() (string, []interface{}, error) {
 	var setClauses []string
-	var updateArgs []interface{}
+	var args []interface{}
 
 	var keys []string
 	for key := range qb.data {
 		keys = append(keys, key)
 	}
-	
+
 	sort.Strings(keys)
 	for _, key := range keys {
 		safeCol, err := EscapeIdentifier(qb.dbType, key)
@@ -84,21 +69,13 @@ func (qb *QueryBuilder) buildMySQLUpdate() (string, []interface{}, error) {
 			return "", nil, err
 		}
 		setClauses = append(setClauses, fmt.Sprintf("%s = ?", safeCol))
-		updateArgs = append(updateArgs, qb.data[key])
+		args = append(args, qb.data[key])
 	}
 
-	setClausesStr := strings.Join(setClauses, ", ")
-	query := fmt.Sprintf("UPDATE %s SET %s", qb.table, setClausesStr)
-
-	allArgs := updateArgs
-	if len(qb.conditions) > 0 {
-		query += " WHERE " + strings.Join(qb.conditions, " AND ")
-		allArgs = append(allArgs, qb.args...)
-	}
-
-	return query, allArgs, nil
+	query := fmt.Sprintf("%s %s SET %s", qb.insertInto(), qb.table, strings.Join(setClauses, ", "))
+	return query, args, nil
 }
 
 func escapeMySQLIdentifier(name string) (string, error) {
-	return "`" + name + "`", nil
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`", nil
 }
\ No newline at end of file
@@ -1,9 +1,11 @@
 package gqbd_test
 
 import (
+	"database/sql"
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/donghquinn/gqbd"
 )
@@ -121,3 +123,1556 @@ func TestBuildDeleteMariaDB(t *testing.T) {
 		t.Errorf("expected args %v, got %v", expectedArgs, args)
 	}
 }
+
+/*
+Build called twice
+
+@ Return: Identical query and arguments on every Build call
+*/
+func TestBuildSelectTwiceMariaDB(t *testing.T) {
+	qb := gqbd.BuildSelect(gqbd.MariaDB, "table_name", "col1").
+		Where("col1 = ?", 100).
+		Limit(10)
+
+	firstQuery, firstArgs, err := qb.Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	secondQuery, secondArgs, err := qb.Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if firstQuery != secondQuery {
+		t.Errorf("expected identical queries, got:\n%s\n%s", firstQuery, secondQuery)
+	}
+	expectedArgs := []interface{}{100, 10}
+	if !reflect.DeepEqual(firstArgs, expectedArgs) || !reflect.DeepEqual(secondArgs, expectedArgs) {
+		t.Errorf("expected args %v on both builds, got %v and %v", expectedArgs, firstArgs, secondArgs)
+	}
+}
+
+/*
+EscapeIdentifier with embedded backticks
+
+@ Return: Identifier with embedded backticks doubled
+*/
+func TestEscapeIdentifierQuotesMariaDB(t *testing.T) {
+	escaped, err := gqbd.EscapeIdentifier(gqbd.MariaDB, "col`; DROP TABLE users; --")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "`col``; DROP TABLE users; --`"
+	if escaped != expected {
+		t.Errorf("expected %s, got %s", expected, escaped)
+	}
+}
+
+/*
+OrderByCollate
+
+@ Return: Multi-column ORDER BY with the collation applied to every column
+*/
+func TestOrderByCollateMariaDB(t *testing.T) {
+	qb := gqbd.BuildSelect(gqbd.MariaDB, "table_name", "col1", "col2").
+		OrderByCollate("utf8mb4_unicode_ci", []gqbd.OrderColumn{
+			{Column: "col1", Direction: "ASC"},
+			{Column: "col2", Direction: "DESC"},
+		}, nil)
+	query, _, err := qb.Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := "SELECT `col1`, `col2` FROM `table_name` ORDER BY `col1` COLLATE utf8mb4_unicode_ci ASC, `col2` COLLATE utf8mb4_unicode_ci DESC"
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+
+	_, _, err = gqbd.BuildSelect(gqbd.MariaDB, "table_name").
+		OrderByCollate("en-US", []gqbd.OrderColumn{{Column: "col1", Direction: "ASC"}}, nil).
+		Build()
+	if err == nil {
+		t.Error("expected error for invalid MariaDB collation, got nil")
+	}
+}
+
+/*
+EscapeIdentifier with qualified names
+
+@ Return: Each segment of table.column and schema.table.column escaped independently
+*/
+func TestEscapeIdentifierQualifiedMariaDB(t *testing.T) {
+	cases := map[string]string{
+		"users.id":      "`users`.`id`",
+		"mydb.users.id": "`mydb`.`users`.`id`",
+		"mydb.users u":  "`mydb`.`users` u",
+		"users AS u":    "`users` AS u",
+	}
+	for name, expected := range cases {
+		escaped, err := gqbd.EscapeIdentifier(gqbd.MariaDB, name)
+		if err != nil {
+			t.Fatalf("unexpected error for %s: %v", name, err)
+		}
+		if escaped != expected {
+			t.Errorf("expected %s for %s, got %s", expected, name, escaped)
+		}
+	}
+}
+
+/*
+PushDownLimit
+
+@ Return: SELECT with the driving table limited in a subquery and its args bound first
+*/
+func TestPushDownLimitMariaDB(t *testing.T) {
+	qb := gqbd.BuildSelect(gqbd.MariaDB, "orders o", "o.id", "c.name").
+		LeftJoin("customers c", "c.id = o.customer_id").
+		Where("o.status = ?", "paid").
+		Limit(10).
+		PushDownLimit()
+	query, args, err := qb.Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := "SELECT `o`.`id`, `c`.`name` FROM (SELECT * FROM `orders` o LIMIT ?) AS o " +
+		"LEFT JOIN `customers` c ON c.id = o.customer_id WHERE o.status = ?"
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{10, "paid"}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("expected args %v, got %v", expectedArgs, args)
+	}
+
+	// Without joins the flag has no effect
+	plainQuery, _, err := gqbd.BuildSelect(gqbd.MariaDB, "orders").Limit(10).PushDownLimit().Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if plainQuery != "SELECT * FROM `orders` LIMIT ?" {
+		t.Errorf("expected plain LIMIT query, got %s", plainQuery)
+	}
+}
+
+/*
+BuildWithCount
+
+@ Return: Paginated SELECT and its COUNT(*) replacement for SQL_CALC_FOUND_ROWS
+*/
+func TestBuildWithCountMariaDB(t *testing.T) {
+	qb := gqbd.BuildSelect(gqbd.MariaDB, "table_name", "col1", "col2").
+		Where("col1 = ?", 100).
+		OrderBy("col1", "ASC", nil).
+		Limit(10).
+		Offset(20)
+	page, count, err := qb.BuildWithCount()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedPage := "SELECT `col1`, `col2` FROM `table_name` WHERE col1 = ? ORDER BY `col1` ASC LIMIT ? OFFSET ?"
+	if page.Query != expectedPage {
+		t.Errorf("expected page query:\n%s\ngot:\n%s", expectedPage, page.Query)
+	}
+	if !reflect.DeepEqual(page.Args, []interface{}{100, 10, 20}) {
+		t.Errorf("expected page args [100 10 20], got %v", page.Args)
+	}
+	expectedCount := "SELECT COUNT(*) FROM `table_name` WHERE col1 = ?"
+	if count.Query != expectedCount {
+		t.Errorf("expected count query:\n%s\ngot:\n%s", expectedCount, count.Query)
+	}
+	if !reflect.DeepEqual(count.Args, []interface{}{100}) {
+		t.Errorf("expected count args [100], got %v", count.Args)
+	}
+
+	_, groupedCount, err := gqbd.BuildSelect(gqbd.MariaDB, "table_name", "col1").
+		GroupBy("col1").
+		Limit(5).
+		BuildWithCount()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedGrouped := "SELECT COUNT(*) FROM (SELECT `col1` FROM `table_name` GROUP BY `col1`) AS count_query"
+	if groupedCount.Query != expectedGrouped {
+		t.Errorf("expected grouped count query:\n%s\ngot:\n%s", expectedGrouped, groupedCount.Query)
+	}
+}
+
+/*
+LockWait
+
+@ Return: Locking SELECT bounded by FOR UPDATE WAIT n seconds
+*/
+func TestLockWaitMariaDB(t *testing.T) {
+	qb := gqbd.BuildSelect(gqbd.MariaDB, "table_name", "col1").
+		Where("col1 = ?", 100).
+		Limit(1).
+		LockWait(1500 * time.Millisecond)
+	query, args, err := qb.Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := "SELECT `col1` FROM `table_name` WHERE col1 = ? LIMIT ? FOR UPDATE WAIT 2"
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+	if !reflect.DeepEqual(args, []interface{}{100, 1}) {
+		t.Errorf("expected args [100 1], got %v", args)
+	}
+
+	if _, _, err := gqbd.BuildSelect(gqbd.MariaDB, "table_name").LockWait(0).Build(); err == nil {
+		t.Error("expected error for non-positive lock wait, got nil")
+	}
+	if _, _, err := gqbd.BuildSelect(gqbd.PostgreSQL, "table_name").LockWait(time.Second).Build(); err == nil {
+		t.Error("expected error for LockWait on PostgreSQL, got nil")
+	}
+}
+
+/*
+InsertIfNotExists
+
+@ Return: INSERT ... SELECT ... FROM DUAL WHERE NOT EXISTS with the key check bound after the values
+*/
+func TestInsertIfNotExistsMariaDB(t *testing.T) {
+	qb := gqbd.BuildInsert(gqbd.MariaDB, "table_name").
+		Values(map[string]interface{}{"email": "a@example.com", "name": "a", "tenant": 7}).
+		InsertIfNotExists("tenant", "email")
+	query, args, err := qb.Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := "INSERT INTO `table_name` (`email`, `name`, `tenant`) SELECT ?, ?, ? FROM DUAL " +
+		"WHERE NOT EXISTS (SELECT 1 FROM `table_name` WHERE `tenant` = ? AND `email` = ?)"
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{"a@example.com", "a", 7, 7, "a@example.com"}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("expected args %v, got %v", expectedArgs, args)
+	}
+
+	_, _, err = gqbd.BuildInsert(gqbd.MariaDB, "table_name").
+		Values(map[string]interface{}{"name": "a"}).
+		InsertIfNotExists("email").
+		Build()
+	if err == nil {
+		t.Error("expected error for key column missing from data, got nil")
+	}
+}
+
+/*
+ToSQL
+
+@ Return: Query with ? placeholders replaced by quoted literals
+*/
+func TestToSQLMariaDB(t *testing.T) {
+	qb := gqbd.BuildSelect(gqbd.MariaDB, "table_name", "col1").
+		Where("name = ? AND age > ?", "it's", 30).
+		Where("active = ? AND deleted_at IS ?", false, nil)
+	sql, err := qb.ToSQL()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "SELECT `col1` FROM `table_name` WHERE name = 'it''s' AND age > 30 AND active = FALSE AND deleted_at IS NULL"
+	if sql != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, sql)
+	}
+}
+
+/*
+MySQLInsertSetSyntax
+
+@ Return: INSERT ... SET query with sorted columns and bound values
+*/
+func TestMySQLInsertSetSyntaxMariaDB(t *testing.T) {
+	qb := gqbd.BuildInsert(gqbd.MariaDB, "table_name").
+		Values(map[string]interface{}{"col2": "test", "col1": 200}).
+		MySQLInsertSetSyntax()
+	query, args, err := qb.Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := "INSERT INTO `table_name` SET `col1` = ?, `col2` = ?"
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{200, "test"}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("expected args %v, got %v", expectedArgs, args)
+	}
+
+	_, _, err = gqbd.BuildInsert(gqbd.PostgreSQL, "table_name").
+		Values(map[string]interface{}{"col1": 200}).
+		MySQLInsertSetSyntax().
+		Build()
+	if err == nil {
+		t.Error("expected error for MySQLInsertSetSyntax on PostgreSQL, got nil")
+	}
+}
+
+/*
+JoinValues
+
+@ Return: SELECT joined against a bound UNION ALL lookup list, bound before WHERE args
+*/
+func TestJoinValuesMariaDB(t *testing.T) {
+	qb := gqbd.BuildSelect(gqbd.MariaDB, "accounts t", "t.id", "s.label").
+		Where("t.region = ?", "eu").
+		JoinValues([][]interface{}{{"A", "Active"}, {"I", "Inactive"}}, "s", []string{"code", "label"}, "t.status = s.code")
+	query, args, err := qb.Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := "SELECT `t`.`id`, `s`.`label` FROM `accounts` t " +
+		"JOIN (SELECT ? AS `code`, ? AS `label` UNION ALL SELECT ?, ?) AS `s` ON t.status = s.code WHERE t.region = ?"
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{"A", "Active", "I", "Inactive", "eu"}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("expected args %v, got %v", expectedArgs, args)
+	}
+}
+
+/*
+WhereNamed
+
+@ Return: SELECT with :name binds resolved to "?", a reused name repeating its arg
+*/
+func TestWhereNamedMariaDB(t *testing.T) {
+	qb := gqbd.BuildSelect(gqbd.MariaDB, "bookings", "id").
+		WhereNamed("starts_at <= :day AND ends_at >= :day AND note <> ':day'",
+			map[string]interface{}{"day": "2024-05-01"})
+	query, args, err := qb.Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := "SELECT `id` FROM `bookings` WHERE starts_at <= ? AND ends_at >= ? AND note <> ':day'"
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{"2024-05-01", "2024-05-01"}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("expected args %v, got %v", expectedArgs, args)
+	}
+}
+
+/*
+Where with slice args
+
+@ Return: SELECT with slice args expanded in place; []byte bound as a single value
+*/
+func TestWhereSliceArgsMariaDB(t *testing.T) {
+	qb := gqbd.BuildSelect(gqbd.MariaDB, "files", "id").
+		Where("owner IN (?) AND checksum = ?", []string{"ann", "bob"}, []byte("abc"))
+	query, args, err := qb.Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := "SELECT `id` FROM `files` WHERE owner IN (?, ?) AND checksum = ?"
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{"ann", "bob", []byte("abc")}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("expected args %v, got %v", expectedArgs, args)
+	}
+}
+
+/*
+GroupByRollup
+
+@ Return: SELECT with GROUP BY ... WITH ROLLUP, and an error for GroupBy after it
+*/
+func TestGroupByRollupMariaDB(t *testing.T) {
+	qb := gqbd.BuildSelect(gqbd.MariaDB, "sales", "region", "product").
+		Aggregate("SUM", "amount").
+		GroupByRollup("region", "product").
+		Having("SUM(amount) > ?", 10)
+	query, args, err := qb.Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := "SELECT `region`, `product`, SUM(`amount`) FROM `sales` GROUP BY `region`, `product` WITH ROLLUP HAVING SUM(amount) > ?"
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{10}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("expected args %v, got %v", expectedArgs, args)
+	}
+
+	if _, _, err := gqbd.BuildSelect(gqbd.MariaDB, "sales").
+		GroupByRollup("region").
+		GroupBy("product").
+		Build(); err == nil {
+		t.Error("expected error for GroupBy after GroupByRollup, got nil")
+	}
+}
+
+/*
+WhereColumn
+
+@ Return: SELECT comparing two backtick-escaped, table-qualified columns
+*/
+func TestWhereColumnMariaDB(t *testing.T) {
+	qb := gqbd.BuildSelect(gqbd.MariaDB, "events a", "a.id").
+		InnerJoin("events b", "a.session_id = b.session_id").
+		WhereColumn("a.created_at", "<=", "b.created_at")
+	query, args, err := qb.Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := "SELECT `a`.`id` FROM `events` a INNER JOIN `events` b ON a.session_id = b.session_id WHERE `a`.`created_at` <= `b`.`created_at`"
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+	if len(args) != 0 {
+		t.Errorf("expected no args, got %v", args)
+	}
+}
+
+/*
+WhereNotBetween
+
+@ Return: SELECT with NOT BETWEEN ? AND ? and args in start, end order
+*/
+func TestWhereNotBetweenMariaDB(t *testing.T) {
+	qb := gqbd.BuildSelect(gqbd.MariaDB, "readings", "id").
+		Where("sensor_id = ?", 7).
+		WhereNotBetween("value", -10, 40)
+	query, args, err := qb.Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := "SELECT `id` FROM `readings` WHERE sensor_id = ? AND `value` NOT BETWEEN ? AND ?"
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{7, -10, 40}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("expected args %v, got %v", expectedArgs, args)
+	}
+}
+
+/*
+SelectWindow
+
+@ Return: SELECT with an OVER clause using backtick-escaped columns
+*/
+func TestSelectWindowMariaDB(t *testing.T) {
+	qb := gqbd.BuildSelect(gqbd.MariaDB, "scores", "player").
+		SelectWindow("DENSE_RANK", "place", nil, "points DESC")
+	query, _, err := qb.Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := "SELECT `player`, DENSE_RANK() OVER (ORDER BY `points` DESC) AS `place` FROM `scores`"
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+}
+
+/*
+BuildTruncate
+
+@ Return: TRUNCATE TABLE; RestartIdentity adds nothing since MariaDB always resets AUTO_INCREMENT
+*/
+func TestBuildTruncateMariaDB(t *testing.T) {
+	for _, qb := range []*gqbd.QueryBuilder{
+		gqbd.BuildTruncate(gqbd.MariaDB, "events"),
+		gqbd.BuildTruncate(gqbd.MariaDB, "events").RestartIdentity(),
+	} {
+		query, args, err := qb.Build()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if expected := "TRUNCATE TABLE `events`"; query != expected {
+			t.Errorf("expected query:\n%s\ngot:\n%s", expected, query)
+		}
+		if len(args) != 0 {
+			t.Errorf("expected no args, got %v", args)
+		}
+	}
+}
+
+/*
+WhereJSONContains
+
+@ Return: SELECT with JSON_CONTAINS, with and without a path
+*/
+func TestWhereJSONContainsMariaDB(t *testing.T) {
+	qb := gqbd.BuildSelect(gqbd.MariaDB, "profiles", "id").
+		WhereJSONContains("tags", "", `"admin"`).
+		WhereJSONContains("settings", "address.city", `"Seoul"`)
+	query, args, err := qb.Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := "SELECT `id` FROM `profiles` WHERE JSON_CONTAINS(`tags`, ?) AND JSON_CONTAINS(`settings`, ?, '$.address.city')"
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{`"admin"`, `"Seoul"`}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("expected args %v, got %v", expectedArgs, args)
+	}
+}
+
+/*
+WhereAny and WhereArrayOverlap
+
+@ Return: Errors because MariaDB has no array operators
+*/
+func TestWhereArrayOperatorsMariaDB(t *testing.T) {
+	if _, _, err := gqbd.BuildSelect(gqbd.MariaDB, "posts").WhereAny("author_id", []int{1}).Build(); err == nil {
+		t.Error("expected error for WhereAny on MariaDB, got nil")
+	}
+	if _, _, err := gqbd.BuildSelect(gqbd.MariaDB, "posts").WhereArrayOverlap("tags", []string{"go"}).Build(); err == nil {
+		t.Error("expected error for WhereArrayOverlap on MariaDB, got nil")
+	}
+}
+
+/*
+WhereFullText
+
+@ Return: SELECT with MATCH ... AGAINST for one and several columns
+*/
+func TestWhereFullTextMariaDB(t *testing.T) {
+	qb := gqbd.BuildSelect(gqbd.MariaDB, "articles", "id").
+		WhereFullText([]string{"title"}, "query builder").
+		WhereFullText([]string{"title", "body"}, "golang")
+	query, args, err := qb.Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := "SELECT `id` FROM `articles` WHERE MATCH(`title`) AGAINST (? IN NATURAL LANGUAGE MODE) AND " +
+		"MATCH(`title`, `body`) AGAINST (? IN NATURAL LANGUAGE MODE)"
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{"query builder", "golang"}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("expected args %v, got %v", expectedArgs, args)
+	}
+}
+
+/*
+CountDistinct
+
+@ Return: SELECT with COUNT(DISTINCT col), with and without an alias
+*/
+func TestCountDistinctMariaDB(t *testing.T) {
+	qb := gqbd.BuildSelect(gqbd.MariaDB, "visits", "page").
+		CountDistinct("v.visitor_id", "unique_visitors").
+		CountDistinct("session_id", "")
+	query, _, err := qb.Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := "SELECT `page`, COUNT(DISTINCT `v`.`visitor_id`) AS `unique_visitors`, COUNT(DISTINCT `session_id`) FROM `visits`"
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+}
+
+/*
+As
+
+@ Return: SELECT with FROM `table` AS `alias`
+*/
+func TestAsMariaDB(t *testing.T) {
+	qb := gqbd.BuildSelect(gqbd.MariaDB, "users", "u.id").
+		As("u").
+		Where("u.id > ?", 10)
+	query, _, err := qb.Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := "SELECT `u`.`id` FROM `users` AS `u` WHERE u.id > ?"
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+}
+
+/*
+Offset without Limit
+
+@ Return: SELECT with MySQL's maximum LIMIT ahead of OFFSET
+*/
+func TestOffsetWithoutLimitMariaDB(t *testing.T) {
+	query, args, err := gqbd.BuildSelect(gqbd.MariaDB, "users", "id").
+		Offset(10).
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := "SELECT `id` FROM `users` LIMIT 18446744073709551615 OFFSET ?"
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{10}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("expected args %v, got %v", expectedArgs, args)
+	}
+}
+
+/*
+Explicit zero Limit
+
+@ Return: SELECT rendering LIMIT 0 only when it was set
+*/
+func TestExplicitZeroLimitMariaDB(t *testing.T) {
+	query, args, err := gqbd.BuildSelect(gqbd.MariaDB, "users", "id").Limit(0).Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := "SELECT `id` FROM `users` LIMIT ?"
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{0}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("expected args %v, got %v", expectedArgs, args)
+	}
+
+	query, args, err = gqbd.BuildSelect(gqbd.MariaDB, "users", "id").Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := "SELECT `id` FROM `users`"; query != expected || len(args) != 0 {
+		t.Errorf("expected %s with no args, got %s %v", expected, query, args)
+	}
+}
+
+/*
+DefaultValues
+
+@ Return: INSERT ... () VALUES () with no args
+*/
+func TestDefaultValuesMariaDB(t *testing.T) {
+	query, args, err := gqbd.BuildInsert(gqbd.MariaDB, "counters").DefaultValues().Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := "INSERT INTO `counters` () VALUES ()"; query != expected {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expected, query)
+	}
+	if len(args) != 0 {
+		t.Errorf("expected no args, got %v", args)
+	}
+}
+
+/*
+BuildInsert with nil values
+
+@ Return: INSERT binding nil as a NULL parameter in sorted column position
+*/
+func TestInsertNilValuesMariaDB(t *testing.T) {
+	query, args, err := gqbd.BuildInsert(gqbd.MariaDB, "users").
+		Values(map[string]interface{}{"name": "Ann", "deleted_at": nil, "age": 30}).
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := "INSERT INTO `users` (`age`, `deleted_at`, `name`) VALUES (?, ?, ?)"
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{30, nil, "Ann"}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("expected args %v, got %v", expectedArgs, args)
+	}
+}
+
+/*
+StrictGroupBy
+
+@ Return: SELECT built when every plain column is grouped, and an error otherwise
+*/
+func TestStrictGroupByMariaDB(t *testing.T) {
+	query, _, err := gqbd.BuildSelect(gqbd.MariaDB, "orders", "customer_id", "region").
+		Aggregate("COUNT", "*").
+		GroupBy("customer_id", "region").
+		StrictGroupBy().
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := "SELECT `customer_id`, `region`, COUNT(*) FROM `orders` GROUP BY `customer_id`, `region`"
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+
+	_, _, err = gqbd.BuildSelect(gqbd.MariaDB, "orders", "customer_id", "status").
+		Aggregate("SUM", "total").
+		GroupBy("customer_id").
+		StrictGroupBy().
+		Build()
+	if err == nil || !strings.Contains(err.Error(), "`status`") {
+		t.Errorf("expected error naming the ungrouped column, got %v", err)
+	}
+
+	if _, _, err := gqbd.BuildSelect(gqbd.MariaDB, "orders", "status").
+		Aggregate("COUNT", "*").
+		StrictGroupBy().
+		Build(); err == nil {
+		t.Error("expected error for an aggregate mixed with a plain column and no GROUP BY, got nil")
+	}
+}
+
+/*
+BuildNamed
+
+@ Return: Query with each "?" rewritten to @pN in order, literals left alone
+*/
+func TestBuildNamedMariaDB(t *testing.T) {
+	query, args, err := gqbd.BuildSelect(gqbd.MariaDB, "users", "id").
+		Where("note <> '?' AND age > ?", 18).
+		Where("status = ?", "active").
+		BuildNamed()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := "SELECT `id` FROM `users` WHERE note <> '?' AND age > @p1 AND status = @p2"
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []sql.NamedArg{sql.Named("p1", 18), sql.Named("p2", "active")}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("expected args %v, got %v", expectedArgs, args)
+	}
+}
+
+/*
+OrderByNulls
+
+@ Return: SELECT emulating NULLS FIRST/LAST with an ISNULL sort key
+*/
+func TestOrderByNullsMariaDB(t *testing.T) {
+	query, _, err := gqbd.BuildSelect(gqbd.MariaDB, "tasks", "id").
+		OrderByNulls("due_at", "ASC", "LAST", nil).
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := "SELECT `id` FROM `tasks` ORDER BY ISNULL(`due_at`) ASC, `due_at` ASC"; query != expected {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expected, query)
+	}
+
+	query, _, err = gqbd.BuildSelect(gqbd.MariaDB, "tasks", "id").
+		OrderByNulls("due_at", "DESC", "first", nil).
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := "SELECT `id` FROM `tasks` ORDER BY ISNULL(`due_at`) DESC, `due_at` DESC"; query != expected {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expected, query)
+	}
+}
+
+/*
+SelectSubquery
+
+@ Return: Scalar subquery column with its args bound before WHERE args
+*/
+func TestSelectSubqueryMariaDB(t *testing.T) {
+	latest := gqbd.BuildSelect(gqbd.MariaDB, "orders", "created_at").
+		WhereRaw("orders.user_id = users.id").
+		Where("status = ?", "paid").
+		OrderBy("created_at", "DESC", nil).
+		Limit(1)
+
+	query, args, err := gqbd.BuildSelect(gqbd.MariaDB, "users", "id").
+		Where("active = ?", true).
+		SelectSubquery(latest, "last_order").
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := "SELECT `id`, (SELECT `created_at` FROM `orders` WHERE orders.user_id = users.id AND status = ? ORDER BY `created_at` DESC LIMIT ?) AS `last_order` FROM `users` WHERE active = ?"
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{"paid", 1, true}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("expected args %v, got %v", expectedArgs, args)
+	}
+}
+
+/*
+Explain
+
+@ Return: SELECT prefixed with EXPLAIN, args unchanged; ExplainAnalyze rejected
+*/
+func TestExplainMariaDB(t *testing.T) {
+	query, args, err := gqbd.BuildSelect(gqbd.MariaDB, "users", "id").
+		Where("age > ?", 18).
+		Explain().
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := "EXPLAIN SELECT `id` FROM `users` WHERE age > ?"
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+	if !reflect.DeepEqual(args, []interface{}{18}) {
+		t.Errorf("expected args [18], got %v", args)
+	}
+
+	if _, _, err := gqbd.BuildSelect(gqbd.MariaDB, "users").ExplainAnalyze().Build(); err == nil {
+		t.Error("expected error for ExplainAnalyze() on MariaDB, got nil")
+	}
+}
+
+/*
+BuildRaw
+
+@ Return: Raw query with "?" placeholders kept as-is and args unchanged
+*/
+func TestBuildRawMariaDB(t *testing.T) {
+	raw := "SELECT id FROM users WHERE age > ? AND status = ? OR referrer_id = ?"
+	query, args, err := gqbd.BuildRaw(gqbd.MariaDB, raw, 18, "active", 7)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if query != raw {
+		t.Errorf("expected query:\n%s\ngot:\n%s", raw, query)
+	}
+	expectedArgs := []interface{}{18, "active", 7}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("expected args %v, got %v", expectedArgs, args)
+	}
+
+	if _, _, err := gqbd.BuildRaw(gqbd.MariaDB, "SELECT id FROM users", 1); err == nil {
+		t.Error("expected error for an arg without a placeholder, got nil")
+	}
+}
+
+/*
+OrderByWithDefault
+
+@ Return: Disallowed column replaced by the configured default, or an error when no default is given
+*/
+func TestOrderByWithDefaultMariaDB(t *testing.T) {
+	allowed := map[string]bool{"created_at": true}
+
+	query, _, err := gqbd.BuildSelect(gqbd.MariaDB, "events", "event_key").
+		OrderByWithDefault("unknown", "DESC", "created_at", allowed).
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := "SELECT `event_key` FROM `events` ORDER BY `created_at` DESC"; query != expected {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expected, query)
+	}
+
+	if _, _, err := gqbd.BuildSelect(gqbd.MariaDB, "events").
+		OrderByWithDefault("unknown", "DESC", "", allowed).
+		Build(); err == nil {
+		t.Error("expected error for a disallowed column without a default, got nil")
+	}
+}
+
+/*
+OrderByStrict
+
+@ Return: Error for a disallowed column, while lenient OrderBy still falls back to id
+*/
+func TestOrderByStrictMariaDB(t *testing.T) {
+	allowed := map[string]bool{"name": true}
+
+	if _, _, err := gqbd.BuildSelect(gqbd.MariaDB, "users", "id").
+		OrderByStrict("password", "DESC", allowed).
+		Build(); err == nil {
+		t.Error("expected error for a disallowed column, got nil")
+	}
+
+	query, _, err := gqbd.BuildSelect(gqbd.MariaDB, "users", "id").
+		OrderBy("password", "DESC", allowed).
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := "SELECT `id` FROM `users` ORDER BY `id` DESC"; query != expected {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expected, query)
+	}
+}
+
+/*
+WhereInTuple
+
+@ Return: Two-column tuple IN with "?" placeholders, rows bound row-major
+*/
+func TestWhereInTupleMariaDB(t *testing.T) {
+	query, args, err := gqbd.BuildSelect(gqbd.MariaDB, "order_items", "sku").
+		WhereInTuple([]string{"order_id", "line_no"}, [][]interface{}{{10, 1}, {11, 2}}).
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := "SELECT `sku` FROM `order_items` WHERE (`order_id`, `line_no`) IN ((?, ?), (?, ?))"
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{10, 1, 11, 2}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("expected args %v, got %v", expectedArgs, args)
+	}
+
+	if _, _, err := gqbd.BuildSelect(gqbd.MariaDB, "order_items").
+		WhereInTuple([]string{"order_id"}, nil).
+		Build(); err == nil {
+		t.Error("expected error for no rows, got nil")
+	}
+}
+
+/*
+Columns
+
+@ Return: Columns replacing the default "*", and explicit "*" kept when asked for
+*/
+func TestColumnsMariaDB(t *testing.T) {
+	query, _, err := gqbd.BuildSelect(gqbd.MariaDB, "users").Columns("id", "name").Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := "SELECT `id`, `name` FROM `users`"; query != expected {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expected, query)
+	}
+
+	query, _, err = gqbd.BuildSelect(gqbd.MariaDB, "users", "*").Columns("name").Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := "SELECT *, `name` FROM `users`"; query != expected {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expected, query)
+	}
+}
+
+/*
+Default "*" replacement / ClearColumns
+
+@ Return: Only the explicitly added columns, and subquery args dropped with cleared columns
+*/
+func TestClearColumnsMariaDB(t *testing.T) {
+	query, _, err := gqbd.BuildSelect(gqbd.MariaDB, "orders").Aggregate("COUNT", "id").Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := "SELECT COUNT(`id`) FROM `orders`"; query != expected {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expected, query)
+	}
+
+	sub := gqbd.BuildSelect(gqbd.MariaDB, "customers", "name").Where("id = ?", 1)
+	query, args, err := gqbd.BuildSelect(gqbd.MariaDB, "orders", "id").
+		SelectSubquery(sub, "customer").
+		ClearColumns().
+		Columns("total").
+		Where("status = ?", "paid").
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := "SELECT `total` FROM `orders` WHERE status = ?"; query != expected {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expected, query)
+	}
+	if !reflect.DeepEqual(args, []interface{}{"paid"}) {
+		t.Errorf("expected args [paid], got %v", args)
+	}
+}
+
+/*
+WhereDateBetween
+
+@ Return: BETWEEN with both time.Time values bound in order
+*/
+func TestWhereDateBetweenMariaDB(t *testing.T) {
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 1, 0)
+
+	query, args, err := gqbd.BuildSelect(gqbd.MariaDB, "orders", "id").
+		WhereDateBetween("created_at", start, end).
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := "SELECT `id` FROM `orders` WHERE `created_at` BETWEEN ? AND ?"
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{start, end}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("expected args %v, got %v", expectedArgs, args)
+	}
+}
+
+/*
+WhereEquals
+
+@ Return: Equality conditions in sorted column order with IS NULL for nil values
+*/
+func TestWhereEqualsMariaDB(t *testing.T) {
+	query, args, err := gqbd.BuildSelect(gqbd.MariaDB, "users", "id").
+		WhereEquals(map[string]interface{}{"status": "active", "manager_id": nil, "age": 30}).
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := "SELECT `id` FROM `users` WHERE `age` = ? AND `manager_id` IS NULL AND `status` = ?"
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{30, "active"}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("expected args %v, got %v", expectedArgs, args)
+	}
+}
+
+/*
+OrderByMap
+
+@ Return: Multi-column ORDER BY with invalid directions defaulting to DESC
+*/
+func TestOrderByMapMariaDB(t *testing.T) {
+	query, _, err := gqbd.BuildSelect(gqbd.MariaDB, "users", "id").
+		OrderByMap([]gqbd.OrderColumn{
+			{Column: "created_at", Direction: "ASC"},
+			{Column: "name", Direction: "sideways"},
+		}, map[string]bool{"name": true, "created_at": true}).
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := "SELECT `id` FROM `users` ORDER BY `created_at` ASC, `name` DESC"; query != expected {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expected, query)
+	}
+
+	if _, _, err := gqbd.BuildSelect(gqbd.MariaDB, "users").
+		OrderByMap([]gqbd.OrderColumn{{Column: "salary", Direction: "DESC"}}, map[string]bool{"name": true}).
+		Build(); err == nil {
+		t.Error("expected error for a disallowed column, got nil")
+	}
+}
+
+/*
+Negative Limit / Offset
+
+@ Return: Error for a negative limit or offset
+*/
+func TestNegativeLimitOffsetMariaDB(t *testing.T) {
+	if _, _, err := gqbd.BuildSelect(gqbd.MariaDB, "users").Limit(-10).Build(); err == nil {
+		t.Error("expected error for a negative limit, got nil")
+	}
+	if _, _, err := gqbd.BuildSelect(gqbd.MariaDB, "users").Offset(-1).Build(); err == nil {
+		t.Error("expected error for a negative offset, got nil")
+	}
+}
+
+/*
+SelectCoalesce
+
+@ Return: COALESCE fallback bound before the WHERE args
+*/
+func TestSelectCoalesceMariaDB(t *testing.T) {
+	query, args, err := gqbd.BuildSelect(gqbd.MariaDB, "products").
+		SelectCoalesce("discount", 0, "discount").
+		Where("category_id = ?", 3).
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := "SELECT COALESCE(`discount`, ?) AS `discount` FROM `products` WHERE category_id = ?"
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{0, 3}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("expected args %v, got %v", expectedArgs, args)
+	}
+}
+
+/*
+WhereInSubquery
+
+@ Return: IN (subquery) with the subquery args in statement order
+*/
+func TestWhereInSubqueryMariaDB(t *testing.T) {
+	sub := gqbd.BuildSelect(gqbd.MariaDB, "orders", "user_id").Where("status = ?", "paid")
+
+	query, args, err := gqbd.BuildSelect(gqbd.MariaDB, "users", "id").
+		Where("active = ?", true).
+		WhereInSubquery("id", sub).
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := "SELECT `id` FROM `users` WHERE active = ? AND `id` IN (SELECT `user_id` FROM `orders` WHERE status = ?)"
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{true, "paid"}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("expected args %v, got %v", expectedArgs, args)
+	}
+}
+
+/*
+WithPlaceholderStyle
+
+@ Return: MariaDB quoting with numbered $N placeholders, including LIMIT
+*/
+func TestWithPlaceholderStyleMariaDB(t *testing.T) {
+	query, args, err := gqbd.BuildSelect(gqbd.MariaDB, "users", "id").
+		WithPlaceholderStyle(gqbd.PlaceholderDollar).
+		Where("age > ? AND status = ?", 18, "active").
+		Limit(5).
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := "SELECT `id` FROM `users` WHERE age > $1 AND status = $2 LIMIT $3"
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{18, "active", 5}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("expected args %v, got %v", expectedArgs, args)
+	}
+}
+
+/*
+String
+
+@ Return: Built query with "?" placeholders and its args
+*/
+func TestStringMariaDB(t *testing.T) {
+	qb := gqbd.BuildSelect(gqbd.MariaDB, "users", "id").Where("id = ?", 7)
+	if expected := "SELECT `id` FROM `users` WHERE id = ? -- args=[7]"; qb.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, qb.String())
+	}
+}
+
+/*
+SetCaseByKey
+
+@ Return: Three-row bulk UPDATE with keys and values bound pairwise, then the IN filter keys
+*/
+func TestSetCaseByKeyMariaDB(t *testing.T) {
+	query, args, err := gqbd.BuildUpdate(gqbd.MariaDB, "users").
+		SetCaseByKey("status", "email", map[interface{}]interface{}{
+			"c@example.com": "banned",
+			"a@example.com": "active",
+			"b@example.com": "inactive",
+		}).
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := "UPDATE `users` SET `status` = CASE `email` WHEN ? THEN ? WHEN ? THEN ? WHEN ? THEN ? ELSE `status` END " +
+		"WHERE `email` IN (?, ?, ?)"
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{
+		"a@example.com", "active", "b@example.com", "inactive", "c@example.com", "banned",
+		"a@example.com", "b@example.com", "c@example.com",
+	}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("expected args %v, got %v", expectedArgs, args)
+	}
+}
+
+/*
+WhereNullSafeEquals
+
+@ Return: <=> with the value bound, including nil
+*/
+func TestWhereNullSafeEqualsMariaDB(t *testing.T) {
+	query, args, err := gqbd.BuildSelect(gqbd.MariaDB, "users", "id").
+		WhereNullSafeEquals("manager_id", nil).
+		WhereNullSafeEquals("team_id", 4).
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := "SELECT `id` FROM `users` WHERE `manager_id` <=> ? AND `team_id` <=> ?"
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{nil, 4}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("expected args %v, got %v", expectedArgs, args)
+	}
+}
+
+/*
+Clause ordering
+
+@ Return: JOIN, WHERE, GROUP BY, HAVING, ORDER BY, LIMIT and OFFSET in that order with args in statement order
+*/
+func TestClauseOrderingMariaDB(t *testing.T) {
+	query, args, err := gqbd.BuildSelect(gqbd.MariaDB, "orders o", "o.customer_id").
+		Aggregate("COUNT", "o.id").
+		Limit(10).
+		Offset(30).
+		OrderBy("o.customer_id", "DESC", nil).
+		GroupBy("o.customer_id").
+		Having("COUNT(o.id) >= ?", 3).
+		Where("o.created_at > ?", "2024-01-01").
+		InnerJoin("customers c", "c.id = o.customer_id").
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := "SELECT `o`.`customer_id`, COUNT(`o`.`id`) FROM `orders` o " +
+		"INNER JOIN `customers` c ON c.id = o.customer_id " +
+		"WHERE o.created_at > ? " +
+		"GROUP BY `o`.`customer_id` " +
+		"HAVING COUNT(o.id) >= ? " +
+		"ORDER BY `o`.`customer_id` DESC " +
+		"LIMIT ? OFFSET ?"
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{"2024-01-01", 3, 10, 30}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("expected args %v, got %v", expectedArgs, args)
+	}
+}
+
+/*
+WhereTimeLTE
+
+@ Return: Local time bound in UTC
+*/
+func TestWhereTimeMariaDB(t *testing.T) {
+	newYork := time.FixedZone("EST", -5*60*60)
+	query, args, err := gqbd.BuildSelect(gqbd.MariaDB, "events", "id").
+		WhereTimeLTE("created_at", time.Date(2024, 1, 1, 19, 0, 0, 0, newYork)).
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := "SELECT `id` FROM `events` WHERE `created_at` <= ?"; query != expected {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expected, query)
+	}
+	expectedArgs := []interface{}{time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("expected args %v, got %v", expectedArgs, args)
+	}
+}
+
+/*
+Suffix
+
+@ Return: Trusted fragment appended last with its args after the WHERE args
+*/
+func TestSuffixMariaDB(t *testing.T) {
+	query, args, err := gqbd.BuildSelect(gqbd.MariaDB, "orders", "id").
+		Where("customer_id = ?", 42).
+		Suffix("LOCK IN SHARE MODE").
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := "SELECT `id` FROM `orders` WHERE customer_id = ? LOCK IN SHARE MODE"; query != expected {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expected, query)
+	}
+	if !reflect.DeepEqual(args, []interface{}{42}) {
+		t.Errorf("expected args [42], got %v", args)
+	}
+
+	query, args, err = gqbd.BuildInsert(gqbd.MariaDB, "counters").
+		Values(map[string]interface{}{"name": "hits", "value": 1}).
+		Suffix("ON DUPLICATE KEY UPDATE `value` = `value` + ?", 1).
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := "INSERT INTO `counters` (`name`, `value`) VALUES (?, ?) ON DUPLICATE KEY UPDATE `value` = `value` + ?"; query != expected {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expected, query)
+	}
+	expectedArgs := []interface{}{"hits", 1, 1}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("expected args %v, got %v", expectedArgs, args)
+	}
+}
+
+/*
+Prefix
+
+@ Return: Tag comment written first with its args bound before the WHERE args
+*/
+func TestPrefixMariaDB(t *testing.T) {
+	query, args, err := gqbd.BuildSelect(gqbd.MariaDB, "orders", "id").
+		Where("status = ?", "paid").
+		Prefix("SET STATEMENT max_statement_time = ? FOR", 5).
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := "SET STATEMENT max_statement_time = ? FOR SELECT `id` FROM `orders` WHERE status = ?"; query != expected {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expected, query)
+	}
+	expectedArgs := []interface{}{5, "paid"}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("expected args %v, got %v", expectedArgs, args)
+	}
+}
+
+/*
+InsertIgnore
+
+@ Return: INSERT IGNORE INTO for both the VALUES and SET forms
+*/
+func TestInsertIgnoreMariaDB(t *testing.T) {
+	data := map[string]interface{}{"email": "ann@example.com", "name": "Ann"}
+
+	query, args, err := gqbd.BuildInsert(gqbd.MariaDB, "users").Values(data).InsertIgnore().Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := "INSERT IGNORE INTO `users` (`email`, `name`) VALUES (?, ?)"; query != expected {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expected, query)
+	}
+	expectedArgs := []interface{}{"ann@example.com", "Ann"}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("expected args %v, got %v", expectedArgs, args)
+	}
+
+	query, _, err = gqbd.BuildInsert(gqbd.MariaDB, "users").Values(data).MySQLInsertSetSyntax().InsertIgnore().Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := "INSERT IGNORE INTO `users` SET `email` = ?, `name` = ?"; query != expected {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expected, query)
+	}
+}
+
+/*
+BuildReplace
+
+@ Return: REPLACE INTO with columns in sorted order, for both the VALUES and SET forms
+*/
+func TestBuildReplaceMariaDB(t *testing.T) {
+	data := map[string]interface{}{"name": "Ann", "id": 1, "email": "ann@example.com"}
+
+	query, args, err := gqbd.BuildReplace(gqbd.MariaDB, "users").Values(data).Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := "REPLACE INTO `users` (`email`, `id`, `name`) VALUES (?, ?, ?)"; query != expected {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expected, query)
+	}
+	expectedArgs := []interface{}{"ann@example.com", 1, "Ann"}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("expected args %v, got %v", expectedArgs, args)
+	}
+
+	query, _, err = gqbd.BuildReplace(gqbd.Mysql, "users").Values(data).MySQLInsertSetSyntax().Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := "REPLACE INTO `users` SET `email` = ?, `id` = ?, `name` = ?"; query != expected {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expected, query)
+	}
+}
+
+/*
+EscapeIdentifierLiteral
+
+@ Return: Whole name quoted as one identifier with backticks doubled
+*/
+func TestEscapeIdentifierLiteralMariaDB(t *testing.T) {
+	split, err := gqbd.EscapeIdentifier(gqbd.MariaDB, "db.table")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := "`db`.`table`"; split != expected {
+		t.Errorf("expected %s, got %s", expected, split)
+	}
+
+	literal, err := gqbd.EscapeIdentifierLiteral(gqbd.MariaDB, "price.`usd`")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := "`price.``usd```"; literal != expected {
+		t.Errorf("expected %s, got %s", expected, literal)
+	}
+}
+
+/*
+Empty Values / Set
+
+@ Return: Errors for an empty data map on INSERT and UPDATE
+*/
+func TestEmptyDataMariaDB(t *testing.T) {
+	if _, _, err := gqbd.BuildInsert(gqbd.MariaDB, "users").Values(map[string]interface{}{}).Build(); err == nil {
+		t.Error("expected error for Values() with an empty map, got nil")
+	}
+	if _, _, err := gqbd.BuildUpdate(gqbd.MariaDB, "users").Set(map[string]interface{}{}).Build(); err == nil {
+		t.Error("expected error for Set() with an empty map, got nil")
+	}
+}
+
+/*
+ApplyFilters
+
+@ Return: Filter conditions and args appended after the existing WHERE args
+*/
+func TestApplyFiltersMariaDB(t *testing.T) {
+	scope := gqbd.FilterFunc(func(qb *gqbd.QueryBuilder) *gqbd.QueryBuilder {
+		return qb.Where("tenant_id = ?", 3).WhereRaw("deleted_at IS NULL")
+	})
+
+	query, args, err := gqbd.BuildSelect(gqbd.MariaDB, "projects", "id").
+		Where("name LIKE ?", "a%").
+		ApplyFilters(scope).
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := "SELECT `id` FROM `projects` WHERE name LIKE ? AND tenant_id = ? AND deleted_at IS NULL"
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{"a%", 3}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("expected args %v, got %v", expectedArgs, args)
+	}
+}
+
+/*
+WithoutSoftDeleted / OnlySoftDeleted
+
+@ Return: IS NULL / IS NOT NULL on the escaped column, ANDed with the other conditions
+*/
+func TestSoftDeleteMariaDB(t *testing.T) {
+	query, args, err := gqbd.BuildUpdate(gqbd.MariaDB, "users").
+		Set(map[string]interface{}{"deleted_at": nil}).
+		OnlySoftDeleted("deleted_at").
+		Where("id = ?", 5).
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := "UPDATE `users` SET `deleted_at` = ? WHERE `deleted_at` IS NOT NULL AND id = ?"; query != expected {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expected, query)
+	}
+	expectedArgs := []interface{}{nil, 5}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("expected args %v, got %v", expectedArgs, args)
+	}
+
+	query, _, err = gqbd.BuildSelect(gqbd.MariaDB, "users", "id").WithoutSoftDeleted("deleted_at").Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := "SELECT `id` FROM `users` WHERE `deleted_at` IS NULL"; query != expected {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expected, query)
+	}
+}
+
+/*
+When with a conditional join
+
+@ Return: Join added only when requested, with WHERE args in order either way
+*/
+func TestWhenJoinMariaDB(t *testing.T) {
+	for _, withOrders := range []bool{true, false} {
+		query, args, err := gqbd.BuildSelect(gqbd.MariaDB, "users u", "u.id").
+			When(withOrders, func(qb *gqbd.QueryBuilder) *gqbd.QueryBuilder {
+				return qb.InnerJoin("orders o", "o.user_id = u.id").Where("o.total > ?", 100)
+			}).
+			Where("u.active = ?", true).
+			Build()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		expectedQuery := "SELECT `u`.`id` FROM `users` u WHERE u.active = ?"
+		expectedArgs := []interface{}{true}
+		if withOrders {
+			expectedQuery = "SELECT `u`.`id` FROM `users` u INNER JOIN `orders` o ON o.user_id = u.id WHERE o.total > ? AND u.active = ?"
+			expectedArgs = []interface{}{100, true}
+		}
+		if query != expectedQuery {
+			t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+		}
+		if !reflect.DeepEqual(args, expectedArgs) {
+			t.Errorf("expected args %v, got %v", expectedArgs, args)
+		}
+	}
+}
+
+/*
+WhereInCSV
+
+@ Return: IN clause from whitespace-padded CSV, and 1 = 0 for an empty one
+*/
+func TestWhereInCSVMariaDB(t *testing.T) {
+	query, args, err := gqbd.BuildSelect(gqbd.MariaDB, "products", "id").WhereInCSV("sku", "a1, b2 ,c3").Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := "SELECT `id` FROM `products` WHERE `sku` IN (?, ?, ?)"; query != expected {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expected, query)
+	}
+	if expected := []interface{}{"a1", "b2", "c3"}; !reflect.DeepEqual(args, expected) {
+		t.Errorf("expected args %v, got %v", expected, args)
+	}
+
+	query, args, err = gqbd.BuildSelect(gqbd.MariaDB, "products", "id").WhereInCSV("sku", "").Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := "SELECT `id` FROM `products` WHERE 1 = 0"; query != expected {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expected, query)
+	}
+	if len(args) != 0 {
+		t.Errorf("expected no args, got %v", args)
+	}
+}
+
+/*
+WithTerminator
+
+@ Return: INSERT with a trailing semicolon when enabled
+*/
+func TestWithTerminatorMariaDB(t *testing.T) {
+	query, args, err := gqbd.BuildInsert(gqbd.MariaDB, "users").
+		Values(map[string]interface{}{"name": "Ann"}).
+		WithTerminator().
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := "INSERT INTO `users` (`name`) VALUES (?);"; query != expected {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expected, query)
+	}
+	if expected := []interface{}{"Ann"}; !reflect.DeepEqual(args, expected) {
+		t.Errorf("expected args %v, got %v", expected, args)
+	}
+}
+
+/*
+Returning
+
+@ Return: INSERT built without RETURNING, since MariaDB and MySQL ignore the call
+*/
+func TestReturningIgnoredMariaDB(t *testing.T) {
+	for _, dbType := range []gqbd.DBType{gqbd.MariaDB, gqbd.Mysql} {
+		query, args, err := gqbd.BuildInsert(dbType, "users").
+			Values(map[string]interface{}{"name": "Ann"}).
+			Returning("id").
+			Build()
+		if err != nil {
+			t.Fatalf("unexpected error for %s: %v", dbType, err)
+		}
+		if expected := "INSERT INTO `users` (`name`) VALUES (?)"; query != expected {
+			t.Errorf("expected query:\n%s\ngot:\n%s", expected, query)
+		}
+		if expected := []interface{}{"Ann"}; !reflect.DeepEqual(args, expected) {
+			t.Errorf("expected args %v, got %v", expected, args)
+		}
+	}
+}
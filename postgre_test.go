@@ -1,9 +1,15 @@
 package gqbd_test
 
 import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/donghquinn/gqbd"
 )
@@ -120,3 +126,2684 @@ func TestBuildDeletePostgreSQL(t *testing.T) {
 		t.Errorf("expected args %v, got %v", expectedArgs, args)
 	}
 }
+
+/*
+Build called twice
+
+@ Return: Identical query and arguments on every Build call
+*/
+func TestBuildSelectTwicePostgreSQL(t *testing.T) {
+	qb := gqbd.BuildSelect(gqbd.PostgreSQL, "table_name", "col1").
+		Where("col1 = ?", 100).
+		Limit(10).
+		Offset(5)
+
+	firstQuery, firstArgs, err := qb.Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	secondQuery, secondArgs, err := qb.Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if firstQuery != secondQuery {
+		t.Errorf("expected identical queries, got:\n%s\n%s", firstQuery, secondQuery)
+	}
+	expectedArgs := []interface{}{100, 10, 5}
+	if !reflect.DeepEqual(firstArgs, expectedArgs) || !reflect.DeepEqual(secondArgs, expectedArgs) {
+		t.Errorf("expected args %v on both builds, got %v and %v", expectedArgs, firstArgs, secondArgs)
+	}
+}
+
+/*
+Clone
+
+@ Return: Independent copy whose changes never affect the original builder
+*/
+func TestClonePostgreSQL(t *testing.T) {
+	base := gqbd.BuildSelect(gqbd.PostgreSQL, "table_name", "col1").
+		Where("col1 = ?", 100)
+	clone := base.Clone().
+		Where("col2 = ?", "extra").
+		Limit(10)
+
+	query, args, err := base.Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := "SELECT \"col1\" FROM \"table_name\" WHERE col1 = $1"
+	if query != expectedQuery {
+		t.Errorf("expected original query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+	if !reflect.DeepEqual(args, []interface{}{100}) {
+		t.Errorf("expected original args [100], got %v", args)
+	}
+
+	cloneQuery, cloneArgs, err := clone.Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedCloneQuery := "SELECT \"col1\" FROM \"table_name\" WHERE col1 = $1 AND col2 = $2 LIMIT $3"
+	if cloneQuery != expectedCloneQuery {
+		t.Errorf("expected clone query:\n%s\ngot:\n%s", expectedCloneQuery, cloneQuery)
+	}
+	expectedCloneArgs := []interface{}{100, "extra", 10}
+	if !reflect.DeepEqual(cloneArgs, expectedCloneArgs) {
+		t.Errorf("expected clone args %v, got %v", expectedCloneArgs, cloneArgs)
+	}
+
+	// Row maps are copied, so editing the caller's rows afterwards leaves the clone alone
+	rows := []map[string]interface{}{{"id": 1, "name": "a"}}
+	update := gqbd.BuildUpdate(gqbd.PostgreSQL, "table_name").UpdateFromValues("id", rows)
+	updateClone := update.Clone()
+	rows[0]["name"] = "changed"
+	_, updateArgs, err := updateClone.Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(updateArgs, []interface{}{1, "a"}) {
+		t.Errorf("expected clone args [1 a], got %v", updateArgs)
+	}
+}
+
+/*
+UpdateFromValues
+
+@ Return: Bulk UPDATE ... FROM (VALUES ...) query with every value bound
+*/
+func TestUpdateFromValuesPostgreSQL(t *testing.T) {
+	rows := []map[string]interface{}{
+		{"id": 1, "name": "a", "score": 10},
+		{"id": 2, "name": "b", "score": 20},
+	}
+	qb := gqbd.BuildUpdate(gqbd.PostgreSQL, "table_name").
+		UpdateFromValues("id", rows).
+		Where("active = ?", true)
+	query, args, err := qb.Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := "UPDATE \"table_name\" SET \"name\" = v.\"name\", \"score\" = v.\"score\" " +
+		"FROM (VALUES ($1, $2, $3), ($4, $5, $6)) AS v(\"id\", \"name\", \"score\") " +
+		"WHERE \"table_name\".\"id\" = v.\"id\" AND active = $7"
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{1, "a", 10, 2, "b", 20, true}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("expected args %v, got %v", expectedArgs, args)
+	}
+
+	_, _, err = gqbd.BuildUpdate(gqbd.PostgreSQL, "table_name").
+		UpdateFromValues("id", []map[string]interface{}{{"id": 1, "name": "a"}, {"name": "b"}}).
+		Build()
+	if err == nil {
+		t.Error("expected error for row missing the key column, got nil")
+	}
+}
+
+/*
+Reset
+
+@ Return: Reset builder producing the same SQL as a freshly created one
+*/
+func TestResetPostgreSQL(t *testing.T) {
+	qb := gqbd.BuildSelect(gqbd.PostgreSQL, "other_table", "other_col").
+		LeftJoin("join_table j", "j.id = other_table.id").
+		Where("other_col = ?", "stale").
+		GroupBy("other_col").
+		Limit(3)
+
+	qb.Reset(gqbd.PostgreSQL, "table_name", "col1").
+		Where("col1 = ?", 100).
+		Limit(10)
+	query, args, err := qb.Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	freshQuery, freshArgs, err := gqbd.BuildSelect(gqbd.PostgreSQL, "table_name", "col1").
+		Where("col1 = ?", 100).
+		Limit(10).
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if query != freshQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", freshQuery, query)
+	}
+	if !reflect.DeepEqual(args, freshArgs) {
+		t.Errorf("expected args %v, got %v", freshArgs, args)
+	}
+}
+
+/*
+Acquire and Release
+
+@ Return: Pooled builder producing the same SQL as a freshly created one
+*/
+func TestAcquireReleasePostgreSQL(t *testing.T) {
+	for i := 0; i < 3; i++ {
+		qb := gqbd.Acquire(gqbd.PostgreSQL, "table_name", "col1").
+			Where("col1 = ?", i)
+		query, args, err := qb.Build()
+		gqbd.Release(qb)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		expectedQuery := "SELECT \"col1\" FROM \"table_name\" WHERE col1 = $1"
+		if query != expectedQuery {
+			t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+		}
+		if !reflect.DeepEqual(args, []interface{}{i}) {
+			t.Errorf("expected args [%d], got %v", i, args)
+		}
+	}
+}
+
+func BenchmarkBuildSelectPostgreSQL(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		qb := gqbd.BuildSelect(gqbd.PostgreSQL, "table_name", "col1", "col2").
+			Where("col1 = ?", 100).
+			Where("col2 = ?", "value")
+		if _, _, err := qb.Build(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkAcquireSelectPostgreSQL(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		qb := gqbd.Acquire(gqbd.PostgreSQL, "table_name", "col1", "col2").
+			Where("col1 = ?", 100).
+			Where("col2 = ?", "value")
+		if _, _, err := qb.Build(); err != nil {
+			b.Fatal(err)
+		}
+		gqbd.Release(qb)
+	}
+}
+
+/*
+TableForTime
+
+@ Return: Partition table name for each granularity and error for invalid input
+*/
+func TestTableForTimePostgreSQL(t *testing.T) {
+	ts := time.Date(2024, time.January, 5, 13, 0, 0, 0, time.UTC)
+	cases := map[string]string{
+		"day":   "logs_2024_01_05",
+		"month": "logs_2024_01",
+		"year":  "logs_2024",
+	}
+	for granularity, expected := range cases {
+		table, err := gqbd.TableForTime("logs", ts, granularity)
+		if err != nil {
+			t.Fatalf("unexpected error for %s: %v", granularity, err)
+		}
+		if table != expected {
+			t.Errorf("expected table %s for %s, got %s", expected, granularity, table)
+		}
+	}
+
+	table, _ := gqbd.TableForTime("logs", ts, "month")
+	query, _, err := gqbd.BuildSelect(gqbd.PostgreSQL, table, "id").Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := "SELECT \"id\" FROM \"logs_2024_01\""
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+
+	if _, err := gqbd.TableForTime("logs", ts, "week"); !errors.Is(err, gqbd.ErrWrongOperation) {
+		t.Errorf("expected ErrWrongOperation for unsupported granularity, got %v", err)
+	}
+	if _, err := gqbd.TableForTime("public.logs", ts, "day"); !errors.Is(err, gqbd.ErrInvalidIdentifier) {
+		t.Errorf("expected ErrInvalidIdentifier for invalid base name, got %v", err)
+	}
+	if _, err := gqbd.TableForTime("", ts, "day"); !errors.Is(err, gqbd.ErrInvalidIdentifier) {
+		t.Errorf("expected ErrInvalidIdentifier for empty base name, got %v", err)
+	}
+}
+
+/*
+EscapeIdentifier with embedded quotes
+
+@ Return: Identifier with embedded double quotes doubled
+*/
+func TestEscapeIdentifierQuotesPostgreSQL(t *testing.T) {
+	escaped, err := gqbd.EscapeIdentifier(gqbd.PostgreSQL, `col"; DROP TABLE users; --`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `"col""; DROP TABLE users; --"`
+	if escaped != expected {
+		t.Errorf("expected %s, got %s", expected, escaped)
+	}
+}
+
+/*
+OrderByCollate
+
+@ Return: Multi-column ORDER BY with the collation applied to every column
+*/
+func TestOrderByCollatePostgreSQL(t *testing.T) {
+	qb := gqbd.BuildSelect(gqbd.PostgreSQL, "table_name", "col1", "col2").
+		OrderByCollate("en_US", []gqbd.OrderColumn{
+			{Column: "col1", Direction: "ASC"},
+			{Column: "col2", Direction: "desc"},
+		}, nil)
+	query, _, err := qb.Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := "SELECT \"col1\", \"col2\" FROM \"table_name\" ORDER BY \"col1\" COLLATE \"en_US\" ASC, \"col2\" COLLATE \"en_US\" DESC"
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+
+	_, _, err = gqbd.BuildSelect(gqbd.PostgreSQL, "table_name").
+		OrderByCollate(`en_US"; --`, []gqbd.OrderColumn{{Column: "col1", Direction: "ASC"}}, nil).
+		Build()
+	if err == nil {
+		t.Error("expected error for invalid collation, got nil")
+	}
+}
+
+/*
+EscapeIdentifier with qualified names
+
+@ Return: Each segment of table.column and schema.table.column escaped independently
+*/
+func TestEscapeIdentifierQualifiedPostgreSQL(t *testing.T) {
+	cases := map[string]string{
+		"users.id":          `"users"."id"`,
+		"public.users.id":   `"public"."users"."id"`,
+		"public.users u":    `"public"."users" u`,
+		"public.users AS u": `"public"."users" AS u`,
+		"u.*":               `"u".*`,
+	}
+	for name, expected := range cases {
+		escaped, err := gqbd.EscapeIdentifier(gqbd.PostgreSQL, name)
+		if err != nil {
+			t.Fatalf("unexpected error for %s: %v", name, err)
+		}
+		if escaped != expected {
+			t.Errorf("expected %s for %s, got %s", expected, name, escaped)
+		}
+	}
+}
+
+/*
+PushDownLimit
+
+@ Return: SELECT with the driving table limited in a subquery before the join
+*/
+func TestPushDownLimitPostgreSQL(t *testing.T) {
+	qb := gqbd.BuildSelect(gqbd.PostgreSQL, "orders o", "o.id", "c.name").
+		LeftJoin("customers c", "c.id = o.customer_id").
+		Where("o.status = ?", "paid").
+		OrderBy("o.created_at", "DESC", nil).
+		Limit(10).
+		Offset(20).
+		PushDownLimit()
+	query, args, err := qb.Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := "SELECT \"o\".\"id\", \"c\".\"name\" FROM (SELECT * FROM \"orders\" o ORDER BY \"o\".\"created_at\" DESC LIMIT $2 OFFSET $3) AS o " +
+		"LEFT JOIN \"customers\" c ON c.id = o.customer_id WHERE o.status = $1 ORDER BY \"o\".\"created_at\" DESC"
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{"paid", 10, 20}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("expected args %v, got %v", expectedArgs, args)
+	}
+}
+
+/*
+EscapeIdentifier validation
+
+@ Return: Error for NUL-containing names and names over the configured length limit
+*/
+func TestEscapeIdentifierValidationPostgreSQL(t *testing.T) {
+	_, _, err := gqbd.BuildSelect(gqbd.PostgreSQL, "table_name").
+		OrderBy("col\x00umn", "ASC", nil).
+		Build()
+	if err == nil || !strings.Contains(err.Error(), "control character") {
+		t.Errorf("expected control character error, got %v", err)
+	}
+
+	gqbd.SetMaxIdentifierLength(63)
+	defer gqbd.SetMaxIdentifierLength(0)
+
+	longName := strings.Repeat("a", 64)
+	_, _, err = gqbd.BuildSelect(gqbd.PostgreSQL, "table_name", longName).Build()
+	if err == nil || !strings.Contains(err.Error(), "maximum length") {
+		t.Errorf("expected maximum length error, got %v", err)
+	}
+	if _, _, err := gqbd.BuildSelect(gqbd.PostgreSQL, "table_name", longName[:63]).Build(); err != nil {
+		t.Errorf("unexpected error for 63-byte identifier: %v", err)
+	}
+}
+
+/*
+PercentileCont
+
+@ Return: SELECT with a PERCENTILE_CONT ordered-set aggregate column
+*/
+func TestPercentileContPostgreSQL(t *testing.T) {
+	qb := gqbd.BuildSelect(gqbd.PostgreSQL, "orders", "region").
+		PercentileCont(0.5, "amount", "median").
+		GroupBy("region")
+	query, _, err := qb.Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := "SELECT \"region\", PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY \"amount\") AS \"median\" FROM \"orders\" GROUP BY \"region\""
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+
+	if _, _, err := gqbd.BuildSelect(gqbd.PostgreSQL, "orders").PercentileCont(1.5, "amount", "p").Build(); err == nil {
+		t.Error("expected error for fraction outside [0,1], got nil")
+	}
+	if _, _, err := gqbd.BuildSelect(gqbd.MariaDB, "orders").PercentileCont(0.5, "amount", "median").Build(); err == nil {
+		t.Error("expected error for PercentileCont on MariaDB, got nil")
+	}
+}
+
+/*
+InsertIfNotExists
+
+@ Return: INSERT ... SELECT ... WHERE NOT EXISTS with the key check bound after the values
+*/
+func TestInsertIfNotExistsPostgreSQL(t *testing.T) {
+	qb := gqbd.BuildInsert(gqbd.PostgreSQL, "table_name").
+		Values(map[string]interface{}{"email": "a@example.com", "name": "a"}).
+		InsertIfNotExists("email")
+	query, args, err := qb.Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := "INSERT INTO \"table_name\" (\"email\", \"name\") SELECT $1, $2 WHERE NOT EXISTS (SELECT 1 FROM \"table_name\" WHERE \"email\" = $3)"
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{"a@example.com", "a", "a@example.com"}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("expected args %v, got %v", expectedArgs, args)
+	}
+}
+
+/*
+SetSchema
+
+@ Return: SELECT with the main and joined tables qualified by the schema
+*/
+func TestSetSchemaPostgreSQL(t *testing.T) {
+	qb := gqbd.BuildSelect(gqbd.PostgreSQL, "users u", "u.id").
+		SetSchema("app").
+		LeftJoin("orders o", "o.user_id = u.id").
+		InnerJoin("audit.events e", "e.user_id = u.id").
+		Where("u.id = ?", 1)
+	query, args, err := qb.Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := "SELECT \"u\".\"id\" FROM \"app\".\"users\" u LEFT JOIN \"app\".\"orders\" o ON o.user_id = u.id " +
+		"INNER JOIN \"audit\".\"events\" e ON e.user_id = u.id WHERE u.id = $1"
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+	if !reflect.DeepEqual(args, []interface{}{1}) {
+		t.Errorf("expected args [1], got %v", args)
+	}
+
+	_, _, err = gqbd.BuildSelect(gqbd.PostgreSQL, "users").
+		LeftJoin("orders", "orders.user_id = users.id").
+		SetSchema("app").
+		Build()
+	if err == nil {
+		t.Error("expected error for SetSchema after joins, got nil")
+	}
+}
+
+/*
+Err and Validate
+
+@ Return: Chain errors visible mid-chain and structural errors reported before Build
+*/
+func TestErrValidatePostgreSQL(t *testing.T) {
+	valid := gqbd.BuildSelect(gqbd.PostgreSQL, "table_name").Where("col1 = ?", 1)
+	if err := valid.Err(); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("expected valid builder, got %v", err)
+	}
+
+	badColumn := gqbd.BuildSelect(gqbd.PostgreSQL, "table_name").OrderBy("", "ASC", nil)
+	if badColumn.Err() == nil {
+		t.Error("expected mid-chain error for empty ORDER BY column, got nil")
+	}
+	if badColumn.Validate() == nil {
+		t.Error("expected Validate to report the chain error, got nil")
+	}
+
+	if err := gqbd.BuildInsert(gqbd.PostgreSQL, "table_name").Validate(); err == nil {
+		t.Error("expected error for INSERT without Values, got nil")
+	}
+	if err := gqbd.BuildUpdate(gqbd.PostgreSQL, "table_name").Validate(); err == nil {
+		t.Error("expected error for UPDATE without Set, got nil")
+	}
+	if err := gqbd.NewQueryBuilder(gqbd.PostgreSQL, "table_name").Validate(); err == nil {
+		t.Error("expected error for builder without an operation, got nil")
+	}
+	insert := gqbd.BuildInsert(gqbd.PostgreSQL, "table_name").Values(map[string]interface{}{"col1": 1})
+	if err := insert.Validate(); err != nil {
+		t.Errorf("expected valid INSERT, got %v", err)
+	}
+}
+
+/*
+ToSQL
+
+@ Return: Query with $N placeholders replaced by quoted literals
+*/
+func TestToSQLPostgreSQL(t *testing.T) {
+	qb := gqbd.BuildSelect(gqbd.PostgreSQL, "table_name", "col1").
+		Where("name = ?", "O'Brien").
+		Where("age > ? AND active = ? AND deleted_at IS ?", 30, true, nil).
+		Limit(10)
+	sql, err := qb.ToSQL()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "SELECT \"col1\" FROM \"table_name\" WHERE name = 'O''Brien' AND age > 30 AND active = TRUE AND deleted_at IS NULL LIMIT 10"
+	if sql != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, sql)
+	}
+}
+
+/*
+MustBuild
+
+@ Return: Query and args for a valid builder, panic for an invalid identifier
+*/
+func TestMustBuildPostgreSQL(t *testing.T) {
+	query, args := gqbd.BuildSelect(gqbd.PostgreSQL, "table_name", "col1").
+		Where("col1 = ?", 100).
+		MustBuild()
+	if query != "SELECT \"col1\" FROM \"table_name\" WHERE col1 = $1" {
+		t.Errorf("unexpected query: %s", query)
+	}
+	if !reflect.DeepEqual(args, []interface{}{100}) {
+		t.Errorf("expected args [100], got %v", args)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected MustBuild to panic for an invalid identifier")
+		}
+	}()
+	gqbd.BuildSelect(gqbd.PostgreSQL, "", "col1").MustBuild()
+}
+
+/*
+WithRowPosition
+
+@ Return: Paginated SELECT carrying each row's absolute ROW_NUMBER position
+*/
+func TestWithRowPositionPostgreSQL(t *testing.T) {
+	qb := gqbd.BuildSelect(gqbd.PostgreSQL, "items", "id", "name").
+		Where("category = ?", "books").
+		OrderBy("name", "ASC", nil).
+		WithRowPosition("position").
+		Limit(20).
+		Offset(40)
+	query, args, err := qb.Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := "SELECT \"id\", \"name\", ROW_NUMBER() OVER (ORDER BY \"name\" ASC) AS \"position\" FROM \"items\" " +
+		"WHERE category = $1 ORDER BY \"name\" ASC LIMIT $2 OFFSET $3"
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{"books", 20, 40}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("expected args %v, got %v", expectedArgs, args)
+	}
+
+	if _, _, err := gqbd.BuildSelect(gqbd.PostgreSQL, "items").WithRowPosition("position").Build(); err == nil {
+		t.Error("expected error for WithRowPosition without OrderBy, got nil")
+	}
+}
+
+type taggedUser struct {
+	ID       int     `db:"id,omitempty"`
+	Name     string  `db:"name"`
+	Email    string  `db:"email,omitempty"`
+	Nickname *string `db:"nickname,omitempty"`
+	Password string  `db:"-"`
+	Internal string
+}
+
+/*
+ValuesStruct
+
+@ Return: INSERT query built from db-tagged struct fields with omitempty honored
+*/
+func TestValuesStructPostgreSQL(t *testing.T) {
+	user := taggedUser{Name: "alice", Email: "alice@example.com", Password: "secret", Internal: "x"}
+	query, args, err := gqbd.BuildInsert(gqbd.PostgreSQL, "users").
+		ValuesStruct(&user).
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(query, "INSERT INTO \"users\" (") {
+		t.Errorf("unexpected query: %s", query)
+	}
+	for _, col := range []string{"\"name\"", "\"email\""} {
+		if !strings.Contains(query, col) {
+			t.Errorf("expected query to contain %s, got %s", col, query)
+		}
+	}
+	for _, col := range []string{"\"id\"", "\"nickname\"", "password", "Internal"} {
+		if strings.Contains(query, col) {
+			t.Errorf("expected query to skip %s, got %s", col, query)
+		}
+	}
+	if len(args) != 2 {
+		t.Errorf("expected 2 args, got %v", args)
+	}
+
+	if _, _, err := gqbd.BuildInsert(gqbd.PostgreSQL, "users").ValuesStruct(42).Build(); err == nil {
+		t.Error("expected error for non-struct value, got nil")
+	}
+}
+
+/*
+SetStruct
+
+@ Return: UPDATE query built from db-tagged struct fields with omitempty honored
+*/
+func TestSetStructPostgreSQL(t *testing.T) {
+	nickname := "al"
+	user := taggedUser{Name: "alice", Nickname: &nickname}
+	query, args, err := gqbd.BuildUpdate(gqbd.PostgreSQL, "users").
+		SetStruct(user).
+		Where("id = ?", 7).
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := "UPDATE \"users\" SET \"name\" = $1, \"nickname\" = $2 WHERE id = $3"
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{"alice", &nickname, 7}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("expected args %v, got %v", expectedArgs, args)
+	}
+}
+
+/*
+JoinValues
+
+@ Return: SELECT joined against a bound VALUES list, numbered before WHERE args
+*/
+func TestJoinValuesPostgreSQL(t *testing.T) {
+	qb := gqbd.BuildSelect(gqbd.PostgreSQL, "accounts t", "t.id", "s.label").
+		Where("t.region = ?", "eu").
+		JoinValues([][]interface{}{{"A", "Active"}, {"I", "Inactive"}}, "s", []string{"code", "label"}, "t.status = s.code").
+		Limit(5)
+	query, args, err := qb.Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := "SELECT \"t\".\"id\", \"s\".\"label\" FROM \"accounts\" t " +
+		"JOIN (VALUES ($1, $2), ($3, $4)) AS \"s\"(\"code\", \"label\") ON t.status = s.code " +
+		"WHERE t.region = $5 LIMIT $6"
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{"A", "Active", "I", "Inactive", "eu", 5}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("expected args %v, got %v", expectedArgs, args)
+	}
+
+	_, _, err = gqbd.BuildSelect(gqbd.PostgreSQL, "accounts t").
+		JoinValues([][]interface{}{{"A"}}, "s", []string{"code", "label"}, "t.status = s.code").
+		Build()
+	if err == nil {
+		t.Error("expected error for row length mismatch, got nil")
+	}
+}
+
+/*
+WhereNamed
+
+@ Return: SELECT with :name binds resolved to $N, a reused name bound once
+*/
+func TestWhereNamedPostgreSQL(t *testing.T) {
+	qb := gqbd.BuildSelect(gqbd.PostgreSQL, "bookings", "id").
+		Where("room_id = ?", 3).
+		WhereNamed("starts_at <= :day AND ends_at >= :day AND kind = :kind::text",
+			map[string]interface{}{"day": "2024-05-01", "kind": "standard"})
+	query, args, err := qb.Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := `SELECT "id" FROM "bookings" WHERE room_id = $1 AND starts_at <= $2 AND ends_at >= $2 AND kind = $3::text`
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{3, "2024-05-01", "standard"}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("expected args %v, got %v", expectedArgs, args)
+	}
+
+	_, _, err = gqbd.BuildSelect(gqbd.PostgreSQL, "bookings").
+		WhereNamed("id = :id", map[string]interface{}{}).
+		Build()
+	if err == nil {
+		t.Error("expected error for a missing named bind, got nil")
+	}
+}
+
+/*
+Where with slice args
+
+@ Return: SELECT with slice args expanded in place and later $N placeholders renumbered
+*/
+func TestWhereSliceArgsPostgreSQL(t *testing.T) {
+	qb := gqbd.BuildSelect(gqbd.PostgreSQL, "orders", "id").
+		Where("tenant_id = ?", 9).
+		Where("status = ? AND id IN (?) AND region = ?", "open", []int{1, 2, 3}, "eu").
+		Where("total > ?", 100)
+	query, args, err := qb.Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := `SELECT "id" FROM "orders" WHERE tenant_id = $1 AND status = $2 AND id IN ($3, $4, $5) AND region = $6 AND total > $7`
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{9, "open", 1, 2, 3, "eu", 100}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("expected args %v, got %v", expectedArgs, args)
+	}
+
+	_, _, err = gqbd.BuildSelect(gqbd.PostgreSQL, "orders").
+		Where("id IN (?)", []int{}).
+		Build()
+	if err == nil {
+		t.Error("expected error for an empty slice arg, got nil")
+	}
+}
+
+// uuidValue is a fixed-size UUID like github.com/google/uuid.UUID.
+type uuidValue [16]byte
+
+// tagList is a slice type that binds itself as one comma-separated value.
+type tagList []string
+
+func (l tagList) Value() (driver.Value, error) {
+	return strings.Join(l, ","), nil
+}
+
+/*
+Where with single-value slice-like args
+
+@ Return: Arrays, byte slices and driver.Valuer slices bound to one placeholder each
+*/
+func TestWhereSingleValueArgsPostgreSQL(t *testing.T) {
+	id := uuidValue{1, 2, 3}
+	doc := json.RawMessage(`{}`)
+	tags := tagList{"a", "b"}
+	query, args, err := gqbd.BuildSelect(gqbd.PostgreSQL, "documents", "id").
+		Where("id = ? AND status IN (?)", id, []string{"draft", "live"}).
+		Where("doc = ?", doc).
+		Where("tags = ?", tags).
+		AddWhereIfNotEmpty("body = ?", doc).
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := `SELECT "id" FROM "documents" WHERE id = $1 AND status IN ($2, $3) AND doc = $4 AND tags = $5 AND body = $6`
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{id, "draft", "live", doc, tags, doc}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("expected args %v, got %v", expectedArgs, args)
+	}
+}
+
+/*
+AddWhereIfNotEmpty
+
+@ Return: SELECT keeping only the non-empty optional filters
+*/
+func TestAddWhereIfNotEmptyPostgreSQL(t *testing.T) {
+	var nilAge *int
+	minAge := 0
+	qb := gqbd.BuildSelect(gqbd.PostgreSQL, "users", "id").
+		AddWhereIfNotEmpty("age = ?", 0).
+		AddWhereIfNotEmpty("score > ?", 0.0).
+		AddWhereIfNotEmpty("id IN (?)", []int{}).
+		AddWhereIfNotEmpty("age >= ?", nilAge).
+		AddWhereIfNotEmpty("name = ?", "").
+		AddWhereIfNotEmpty("level = ?", 3).
+		AddWhereIfNotEmpty("group_id IN (?)", []int{4, 5}).
+		AddWhereIfNotEmpty("age > ?", &minAge).
+		AddWhereIfNotEmpty("active = ?", false)
+	query, args, err := qb.Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := `SELECT "id" FROM "users" WHERE level = $1 AND group_id IN ($2, $3) AND age > $4 AND active = $5`
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{3, 4, 5, &minAge, false}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("expected args %v, got %v", expectedArgs, args)
+	}
+}
+
+/*
+When
+
+@ Return: SELECT with only the applied branch's clauses, and fn errors propagated
+*/
+func TestWhenPostgreSQL(t *testing.T) {
+	search, status := "ann", ""
+	qb := gqbd.BuildSelect(gqbd.PostgreSQL, "users", "id").
+		When(search != "", func(b *gqbd.QueryBuilder) *gqbd.QueryBuilder {
+			return b.Where("name ILIKE ?", "%"+search+"%")
+		}).
+		When(status != "", func(b *gqbd.QueryBuilder) *gqbd.QueryBuilder {
+			return b.Where("status = ?", status)
+		}).
+		Where("active = ?", true)
+	query, args, err := qb.Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := `SELECT "id" FROM "users" WHERE name ILIKE $1 AND active = $2`
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{"%ann%", true}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("expected args %v, got %v", expectedArgs, args)
+	}
+
+	_, _, err = gqbd.BuildSelect(gqbd.PostgreSQL, "users").
+		When(true, func(b *gqbd.QueryBuilder) *gqbd.QueryBuilder {
+			return b.WhereIn("bad\x00col", []interface{}{1})
+		}).
+		Build()
+	if err == nil {
+		t.Error("expected error from the applied branch, got nil")
+	}
+}
+
+/*
+HavingAggregate
+
+@ Return: SELECT with an escaped aggregate HAVING, and errors for bad input or a missing GROUP BY
+*/
+func TestHavingAggregatePostgreSQL(t *testing.T) {
+	qb := gqbd.BuildSelect(gqbd.PostgreSQL, "orders", "customer_id").
+		Where("status = ?", "paid").
+		GroupBy("customer_id").
+		HavingAggregate("sum", "total", ">=", 500).
+		HavingAggregate("COUNT", "*", ">", 2)
+	query, args, err := qb.Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := `SELECT "customer_id" FROM "orders" WHERE status = $1 GROUP BY "customer_id" HAVING SUM("total") >= $2 AND COUNT(*) > $3`
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{"paid", 500, 2}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("expected args %v, got %v", expectedArgs, args)
+	}
+
+	if _, _, err := gqbd.BuildSelect(gqbd.PostgreSQL, "orders").
+		Having("COUNT(*) > ?", 5).
+		Build(); err == nil {
+		t.Error("expected error for Having without GroupBy, got nil")
+	}
+	if _, _, err := gqbd.BuildSelect(gqbd.PostgreSQL, "orders").
+		GroupBy("customer_id").
+		HavingAggregate("COUNT(1); DROP TABLE orders; --", "id", ">", 1).
+		Build(); err == nil {
+		t.Error("expected error for an unknown aggregate function, got nil")
+	}
+	if _, _, err := gqbd.BuildSelect(gqbd.PostgreSQL, "orders").
+		GroupBy("customer_id").
+		HavingAggregate("SUM", "total", "LIKE", 1).
+		Build(); err == nil {
+		t.Error("expected error for an unknown operator, got nil")
+	}
+}
+
+/*
+GroupByRollup
+
+@ Return: SELECT with GROUP BY ROLLUP (...) after plain GROUP BY columns
+*/
+func TestGroupByRollupPostgreSQL(t *testing.T) {
+	qb := gqbd.BuildSelect(gqbd.PostgreSQL, "sales", "region", "product").
+		Aggregate("SUM", "amount").
+		GroupBy("year").
+		GroupByRollup("region", "product")
+	query, _, err := qb.Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := `SELECT "region", "product", SUM("amount") FROM "sales" GROUP BY "year", ROLLUP ("region", "product")`
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+
+	if _, _, err := gqbd.BuildSelect(gqbd.SQLite, "sales").GroupByRollup("region").Build(); err == nil {
+		t.Error("expected error for GroupByRollup on SQLite, got nil")
+	}
+}
+
+/*
+WhereRaw
+
+@ Return: SELECT with a raw fragment numbered in sequence with regular Where conditions
+*/
+func TestWhereRawPostgreSQL(t *testing.T) {
+	qb := gqbd.BuildSelect(gqbd.PostgreSQL, "places", "id").
+		Where("kind = ?", "cafe").
+		WhereRaw("ST_DWithin(geom, ST_MakePoint(?, ?)::geography, ?)", 126.97, 37.56, 500).
+		Where("open = ?", true)
+	query, args, err := qb.Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := `SELECT "id" FROM "places" WHERE kind = $1 AND ST_DWithin(geom, ST_MakePoint($2, $3)::geography, $4) AND open = $5`
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{"cafe", 126.97, 37.56, 500, true}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("expected args %v, got %v", expectedArgs, args)
+	}
+}
+
+/*
+WhereColumn
+
+@ Return: SELECT comparing two escaped, table-qualified columns without binding args
+*/
+func TestWhereColumnPostgreSQL(t *testing.T) {
+	qb := gqbd.BuildSelect(gqbd.PostgreSQL, "events a", "a.id").
+		InnerJoin("events b", "a.session_id = b.session_id").
+		WhereColumn("a.created_at", ">", "b.created_at").
+		Where("a.kind = ?", "click")
+	query, args, err := qb.Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := `SELECT "a"."id" FROM "events" a INNER JOIN "events" b ON a.session_id = b.session_id WHERE "a"."created_at" > "b"."created_at" AND a.kind = $1`
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{"click"}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("expected args %v, got %v", expectedArgs, args)
+	}
+
+	if _, _, err := gqbd.BuildSelect(gqbd.PostgreSQL, "events").
+		WhereColumn("a", "= 1 OR 1 =", "b").
+		Build(); err == nil {
+		t.Error("expected error for an unknown operator, got nil")
+	}
+}
+
+/*
+WhereBetween and WhereNotBetween
+
+@ Return: SELECT with range placeholders numbered after the args already bound
+*/
+func TestWhereNotBetweenPostgreSQL(t *testing.T) {
+	qb := gqbd.BuildSelect(gqbd.PostgreSQL, "readings", "id").
+		Where("sensor_id = ? AND unit = ?", 7, "C").
+		WhereNotBetween("value", -10, 40).
+		WhereBetween("taken_at", "2024-01-01", "2024-02-01")
+	query, args, err := qb.Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := `SELECT "id" FROM "readings" WHERE sensor_id = $1 AND unit = $2 AND "value" NOT BETWEEN $3 AND $4 AND "taken_at" BETWEEN $5 AND $6`
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{7, "C", -10, 40, "2024-01-01", "2024-02-01"}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("expected args %v, got %v", expectedArgs, args)
+	}
+}
+
+/*
+SelectWindow
+
+@ Return: SELECT with an escaped OVER (PARTITION BY ... ORDER BY ...) column
+*/
+func TestSelectWindowPostgreSQL(t *testing.T) {
+	qb := gqbd.BuildSelect(gqbd.PostgreSQL, "orders", "id", "customer_id").
+		SelectWindow("ROW_NUMBER", "rn", []string{"customer_id"}, "created_at DESC, id").
+		SelectWindow("lag(total)", "prev_total", []string{"customer_id"}, "created_at")
+	query, _, err := qb.Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := `SELECT "id", "customer_id", ` +
+		`ROW_NUMBER() OVER (PARTITION BY "customer_id" ORDER BY "created_at" DESC, "id" ASC) AS "rn", ` +
+		`LAG("total") OVER (PARTITION BY "customer_id" ORDER BY "created_at" ASC) AS "prev_total" FROM "orders"`
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+
+	if _, _, err := gqbd.BuildSelect(gqbd.PostgreSQL, "orders").
+		SelectWindow("pg_sleep(10)", "x", nil, "").
+		Build(); err == nil {
+		t.Error("expected error for a function outside the allow-list, got nil")
+	}
+}
+
+/*
+BuildTruncate
+
+@ Return: TRUNCATE TABLE with and without RESTART IDENTITY, and no args
+*/
+func TestBuildTruncatePostgreSQL(t *testing.T) {
+	query, args, err := gqbd.BuildTruncate(gqbd.PostgreSQL, "audit.events").Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := `TRUNCATE TABLE "audit"."events"`; query != expected {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expected, query)
+	}
+	if len(args) != 0 {
+		t.Errorf("expected no args, got %v", args)
+	}
+
+	query, _, err = gqbd.BuildTruncate(gqbd.PostgreSQL, "events").RestartIdentity().Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := `TRUNCATE TABLE "events" RESTART IDENTITY`; query != expected {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expected, query)
+	}
+
+	if _, _, err := gqbd.BuildDelete(gqbd.PostgreSQL, "events").RestartIdentity().Build(); err == nil {
+		t.Error("expected error for RestartIdentity on DELETE, got nil")
+	}
+	if _, _, err := gqbd.BuildTruncate(gqbd.SQLite, "events").Build(); err == nil {
+		t.Error("expected error for TRUNCATE on SQLite, got nil")
+	}
+}
+
+/*
+WhereJSONContains
+
+@ Return: SELECT with @> for whole documents and -> for key paths, values bound
+*/
+func TestWhereJSONContainsPostgreSQL(t *testing.T) {
+	qb := gqbd.BuildSelect(gqbd.PostgreSQL, "profiles", "id").
+		Where("active = ?", true).
+		WhereJSONContains("settings", "", `{"beta": true}`).
+		WhereJSONContains("settings", "address.city", `"Seoul"`)
+	query, args, err := qb.Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := `SELECT "id" FROM "profiles" WHERE active = $1 AND "settings" @> $2 AND "settings"->'address'->'city' = $3`
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{true, `{"beta": true}`, `"Seoul"`}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("expected args %v, got %v", expectedArgs, args)
+	}
+
+	if _, _, err := gqbd.BuildSelect(gqbd.PostgreSQL, "profiles").
+		WhereJSONContains("settings", "a' OR '1'='1", "1").
+		Build(); err == nil {
+		t.Error("expected error for an invalid JSON path, got nil")
+	}
+}
+
+/*
+WhereAny and WhereArrayOverlap
+
+@ Return: SELECT with = ANY and && operators, each array bound as one arg
+*/
+func TestWhereArrayOperatorsPostgreSQL(t *testing.T) {
+	ids := []int64{1, 2, 3}
+	tags := []string{"go", "sql"}
+	qb := gqbd.BuildSelect(gqbd.PostgreSQL, "posts", "id").
+		WhereAny("author_id", ids).
+		WhereArrayOverlap("tags", tags)
+	query, args, err := qb.Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := `SELECT "id" FROM "posts" WHERE "author_id" = ANY($1) AND "tags" && $2`
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{ids, tags}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("expected args %v, got %v", expectedArgs, args)
+	}
+}
+
+/*
+WhereFullText
+
+@ Return: SELECT with to_tsvector/plainto_tsquery for one and several columns
+*/
+func TestWhereFullTextPostgreSQL(t *testing.T) {
+	qb := gqbd.BuildSelect(gqbd.PostgreSQL, "articles", "id").
+		WhereFullText([]string{"title"}, "query builder").
+		WhereFullText([]string{"title", "body"}, "golang")
+	query, args, err := qb.Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := `SELECT "id" FROM "articles" WHERE to_tsvector("title") @@ plainto_tsquery($1) AND ` +
+		`to_tsvector(concat_ws(' ', "title", "body")) @@ plainto_tsquery($2)`
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{"query builder", "golang"}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("expected args %v, got %v", expectedArgs, args)
+	}
+}
+
+/*
+CountDistinct
+
+@ Return: SELECT with COUNT(DISTINCT col) and an escaped alias
+*/
+func TestCountDistinctPostgreSQL(t *testing.T) {
+	qb := gqbd.BuildSelect(gqbd.PostgreSQL, "visits", "page").
+		CountDistinct("visitor_id", "unique_visitors").
+		GroupBy("page")
+	query, _, err := qb.Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := `SELECT "page", COUNT(DISTINCT "visitor_id") AS "unique_visitors" FROM "visits" GROUP BY "page"`
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+}
+
+/*
+As
+
+@ Return: SELECT with FROM table AS alias and alias-qualified conditions left untouched
+*/
+func TestAsPostgreSQL(t *testing.T) {
+	qb := gqbd.BuildSelect(gqbd.PostgreSQL, "users", "u.id", "p.title").
+		As("u").
+		InnerJoin("posts p", "p.user_id = u.id").
+		Where("u.active = ?", true)
+	query, args, err := qb.Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := `SELECT "u"."id", "p"."title" FROM "users" AS "u" INNER JOIN "posts" p ON p.user_id = u.id WHERE u.active = $1`
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{true}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("expected args %v, got %v", expectedArgs, args)
+	}
+
+	query, _, err = gqbd.BuildSelect(gqbd.PostgreSQL, "users").As("u").SetSchema("app").Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := `SELECT * FROM "app"."users" AS "u"`; query != expected {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expected, query)
+	}
+
+	if _, _, err := gqbd.BuildSelect(gqbd.PostgreSQL, "users u").As("x").Build(); err == nil {
+		t.Error("expected error for a table that already has an alias, got nil")
+	}
+	if _, _, err := gqbd.BuildSelect(gqbd.PostgreSQL, "users").As("u; DROP TABLE users").Build(); err == nil {
+		t.Error("expected error for an invalid alias, got nil")
+	}
+}
+
+/*
+HavingRaw
+
+@ Return: SELECT with a multi-aggregate HAVING numbered after the WHERE args
+*/
+func TestHavingRawPostgreSQL(t *testing.T) {
+	qb := gqbd.BuildSelect(gqbd.PostgreSQL, "orders", "customer_id").
+		Where("status = ?", "paid").
+		GroupBy("customer_id").
+		HavingRaw("SUM(total) > ? OR (COUNT(*) >= ? AND MAX(total) > ?)", 1000, 10, 200)
+	query, args, err := qb.Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := `SELECT "customer_id" FROM "orders" WHERE status = $1 GROUP BY "customer_id" HAVING SUM(total) > $2 OR (COUNT(*) >= $3 AND MAX(total) > $4)`
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{"paid", 1000, 10, 200}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("expected args %v, got %v", expectedArgs, args)
+	}
+}
+
+/*
+Offset without Limit
+
+@ Return: SELECT with a standalone OFFSET
+*/
+func TestOffsetWithoutLimitPostgreSQL(t *testing.T) {
+	query, args, err := gqbd.BuildSelect(gqbd.PostgreSQL, "users", "id").
+		Where("active = ?", true).
+		Offset(10).
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := `SELECT "id" FROM "users" WHERE active = $1 OFFSET $2`
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{true, 10}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("expected args %v, got %v", expectedArgs, args)
+	}
+}
+
+/*
+Explicit zero Limit and Offset
+
+@ Return: SELECT rendering LIMIT 0/OFFSET 0 only when they were set
+*/
+func TestExplicitZeroLimitPostgreSQL(t *testing.T) {
+	query, args, err := gqbd.BuildSelect(gqbd.PostgreSQL, "users", "id").Limit(0).Offset(0).Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := `SELECT "id" FROM "users" LIMIT $1 OFFSET $2`
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{0, 0}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("expected args %v, got %v", expectedArgs, args)
+	}
+
+	query, args, err = gqbd.BuildSelect(gqbd.PostgreSQL, "users", "id").Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := `SELECT "id" FROM "users"`; query != expected || len(args) != 0 {
+		t.Errorf("expected %s with no args, got %s %v", expected, query, args)
+	}
+}
+
+/*
+Sentinel errors
+
+@ Return: Builder errors matching ErrInvalidIdentifier, ErrWrongOperation and ErrNoData via errors.Is
+*/
+func TestSentinelErrorsPostgreSQL(t *testing.T) {
+	_, _, err := gqbd.BuildSelect(gqbd.PostgreSQL, "users", "bad\x00col").Build()
+	if !errors.Is(err, gqbd.ErrInvalidIdentifier) {
+		t.Errorf("expected ErrInvalidIdentifier, got %v", err)
+	}
+	if err == nil || !strings.Contains(err.Error(), "control character") {
+		t.Errorf("expected the descriptive message to be kept, got %v", err)
+	}
+
+	_, _, err = gqbd.BuildUpdate(gqbd.PostgreSQL, "users").Values(map[string]interface{}{"a": 1}).Build()
+	if !errors.Is(err, gqbd.ErrWrongOperation) {
+		t.Errorf("expected ErrWrongOperation, got %v", err)
+	}
+
+	_, _, err = gqbd.BuildInsert(gqbd.PostgreSQL, "users").Build()
+	if !errors.Is(err, gqbd.ErrNoData) {
+		t.Errorf("expected ErrNoData, got %v", err)
+	}
+	if errors.Is(err, gqbd.ErrWrongOperation) {
+		t.Errorf("expected ErrNoData not to match ErrWrongOperation")
+	}
+}
+
+/*
+DefaultValues
+
+@ Return: INSERT ... DEFAULT VALUES with RETURNING and no args
+*/
+func TestDefaultValuesPostgreSQL(t *testing.T) {
+	query, args, err := gqbd.BuildInsert(gqbd.PostgreSQL, "counters").
+		DefaultValues().
+		Returning("id").
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := `INSERT INTO "counters" DEFAULT VALUES RETURNING id`; query != expected {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expected, query)
+	}
+	if len(args) != 0 {
+		t.Errorf("expected no args, got %v", args)
+	}
+
+	if _, _, err := gqbd.BuildInsert(gqbd.PostgreSQL, "counters").
+		Values(map[string]interface{}{"n": 1}).
+		DefaultValues().
+		Build(); err == nil {
+		t.Error("expected error for DefaultValues combined with Values, got nil")
+	}
+}
+
+/*
+BuildInsert with nil values
+
+@ Return: INSERT binding nil as a NULL parameter in sorted column position
+*/
+func TestInsertNilValuesPostgreSQL(t *testing.T) {
+	query, args, err := gqbd.BuildInsert(gqbd.PostgreSQL, "users").
+		Values(map[string]interface{}{"name": "Ann", "deleted_at": nil, "age": 30, "bio": nil}).
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := `INSERT INTO "users" ("age", "bio", "deleted_at", "name") VALUES ($1, $2, $3, $4)`
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{30, nil, nil, "Ann"}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("expected args %v, got %v", expectedArgs, args)
+	}
+}
+
+/*
+BuildNamed
+
+@ Return: Query with $N rewritten to @pN and args wrapped as sql.NamedArg
+*/
+func TestBuildNamedPostgreSQL(t *testing.T) {
+	query, args, err := gqbd.BuildSelect(gqbd.PostgreSQL, "users", "id").
+		Where("status = ?", "active").
+		WhereIn("role", []interface{}{"admin", "owner"}).
+		Limit(5).
+		BuildNamed()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := `SELECT "id" FROM "users" WHERE status = @p1 AND "role" IN (@p2, @p3) LIMIT @p4`
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []sql.NamedArg{
+		sql.Named("p1", "active"),
+		sql.Named("p2", "admin"),
+		sql.Named("p3", "owner"),
+		sql.Named("p4", 5),
+	}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("expected args %v, got %v", expectedArgs, args)
+	}
+}
+
+/*
+OrderByNulls
+
+@ Return: SELECT with ORDER BY ... NULLS LAST, and an error for an invalid nulls option
+*/
+func TestOrderByNullsPostgreSQL(t *testing.T) {
+	query, _, err := gqbd.BuildSelect(gqbd.PostgreSQL, "tasks", "id").
+		OrderByNulls("due_at", "desc", "last", map[string]bool{"due_at": true}).
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := `SELECT "id" FROM "tasks" ORDER BY "due_at" DESC NULLS LAST`; query != expected {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expected, query)
+	}
+
+	if _, _, err := gqbd.BuildSelect(gqbd.PostgreSQL, "tasks").
+		OrderByNulls("due_at", "ASC", "MIDDLE", nil).
+		Build(); err == nil {
+		t.Error("expected error for an invalid nulls option, got nil")
+	}
+}
+
+/*
+SelectSubquery
+
+@ Return: Correlated scalar subquery column with its args numbered ahead of WHERE args
+*/
+func TestSelectSubqueryPostgreSQL(t *testing.T) {
+	teamName := gqbd.BuildSelect(gqbd.PostgreSQL, "teams", "name").
+		WhereRaw("teams.id = users.team_id").
+		Where("status = ?", "active").
+		Limit(1)
+
+	query, args, err := gqbd.BuildSelect(gqbd.PostgreSQL, "users", "id").
+		Where("active = ?", true).
+		SelectSubquery(teamName, "team_name").
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := `SELECT "id", (SELECT "name" FROM "teams" WHERE teams.id = users.team_id AND status = $1 LIMIT $2) AS "team_name" FROM "users" WHERE active = $3`
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{"active", 1, true}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("expected args %v, got %v", expectedArgs, args)
+	}
+
+	invalid := gqbd.BuildSelect(gqbd.PostgreSQL, "teams", "name").Having("COUNT(*) > ?", 1)
+	if _, _, err := gqbd.BuildSelect(gqbd.PostgreSQL, "users", "id").
+		SelectSubquery(invalid, "team_name").
+		Build(); err == nil {
+		t.Error("expected the subquery error to propagate, got nil")
+	}
+}
+
+/*
+Explain / ExplainAnalyze
+
+@ Return: SELECT prefixed with EXPLAIN or EXPLAIN ANALYZE, args unchanged
+*/
+func TestExplainPostgreSQL(t *testing.T) {
+	query, args, err := gqbd.BuildSelect(gqbd.PostgreSQL, "users", "id").
+		Where("age > ?", 18).
+		Limit(10).
+		Explain().
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := `EXPLAIN SELECT "id" FROM "users" WHERE age > $1 LIMIT $2`
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{18, 10}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("expected args %v, got %v", expectedArgs, args)
+	}
+
+	query, args, err = gqbd.BuildSelect(gqbd.PostgreSQL, "users", "id").
+		Where("age > ?", 18).
+		ExplainAnalyze().
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery = `EXPLAIN ANALYZE SELECT "id" FROM "users" WHERE age > $1`
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+	if !reflect.DeepEqual(args, []interface{}{18}) {
+		t.Errorf("expected args [18], got %v", args)
+	}
+
+	if _, _, err := gqbd.BuildTruncate(gqbd.PostgreSQL, "users").Explain().Build(); err == nil {
+		t.Error("expected error for Explain() on TRUNCATE, got nil")
+	}
+}
+
+/*
+BuildRaw
+
+@ Return: Raw query with every "?" converted to $N and args unchanged
+*/
+func TestBuildRawPostgreSQL(t *testing.T) {
+	query, args, err := gqbd.BuildRaw(gqbd.PostgreSQL,
+		"SELECT id FROM users WHERE age > ? AND status = ? OR referrer_id = ?", 18, "active", 7)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := "SELECT id FROM users WHERE age > $1 AND status = $2 OR referrer_id = $3"
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{18, "active", 7}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("expected args %v, got %v", expectedArgs, args)
+	}
+
+	if _, _, err := gqbd.BuildRaw(gqbd.PostgreSQL, "SELECT id FROM users WHERE id = ?"); err == nil {
+		t.Error("expected error for a placeholder without an arg, got nil")
+	}
+}
+
+/*
+OrderByWithDefault
+
+@ Return: Disallowed column replaced by the configured default, or an error when no default is given
+*/
+func TestOrderByWithDefaultPostgreSQL(t *testing.T) {
+	allowed := map[string]bool{"created_at": true, "sku": true}
+
+	query, _, err := gqbd.BuildSelect(gqbd.PostgreSQL, "products", "sku").
+		OrderByWithDefault("password", "ASC", "sku", allowed).
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := `SELECT "sku" FROM "products" ORDER BY "sku" ASC`; query != expected {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expected, query)
+	}
+
+	query, _, err = gqbd.BuildSelect(gqbd.PostgreSQL, "products", "sku").
+		OrderByWithDefault("created_at", "DESC", "sku", allowed).
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := `SELECT "sku" FROM "products" ORDER BY "created_at" DESC`; query != expected {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expected, query)
+	}
+
+	_, _, err = gqbd.BuildSelect(gqbd.PostgreSQL, "products", "sku").
+		OrderByWithDefault("password", "ASC", "", allowed).
+		Build()
+	if !errors.Is(err, gqbd.ErrInvalidIdentifier) {
+		t.Errorf("expected ErrInvalidIdentifier without a default column, got %v", err)
+	}
+}
+
+/*
+OrderByStrict
+
+@ Return: Error naming a disallowed column, while lenient OrderBy still falls back to id
+*/
+func TestOrderByStrictPostgreSQL(t *testing.T) {
+	allowed := map[string]bool{"name": true}
+
+	query, _, err := gqbd.BuildSelect(gqbd.PostgreSQL, "users", "id").
+		OrderByStrict("name", "ASC", allowed).
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := `SELECT "id" FROM "users" ORDER BY "name" ASC`; query != expected {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expected, query)
+	}
+
+	_, _, err = gqbd.BuildSelect(gqbd.PostgreSQL, "users", "id").
+		OrderByStrict("password", "ASC", allowed).
+		Build()
+	if err == nil || !strings.Contains(err.Error(), `"password"`) {
+		t.Errorf("expected error naming the rejected column, got %v", err)
+	}
+
+	query, _, err = gqbd.BuildSelect(gqbd.PostgreSQL, "users", "id").
+		OrderBy("password", "ASC", allowed).
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := `SELECT "id" FROM "users" ORDER BY "id" ASC`; query != expected {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expected, query)
+	}
+}
+
+/*
+WhereInTuple
+
+@ Return: Two-column tuple IN numbered after earlier args, rows bound row-major
+*/
+func TestWhereInTuplePostgreSQL(t *testing.T) {
+	query, args, err := gqbd.BuildSelect(gqbd.PostgreSQL, "order_items", "order_id", "line_no", "sku").
+		Where("status = ?", "open").
+		WhereInTuple([]string{"order_id", "line_no"}, [][]interface{}{{10, 1}, {10, 2}, {11, 1}}).
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := `SELECT "order_id", "line_no", "sku" FROM "order_items" WHERE status = $1 AND ("order_id", "line_no") IN (($2, $3), ($4, $5), ($6, $7))`
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{"open", 10, 1, 10, 2, 11, 1}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("expected args %v, got %v", expectedArgs, args)
+	}
+
+	if _, _, err := gqbd.BuildSelect(gqbd.PostgreSQL, "order_items").
+		WhereInTuple([]string{"order_id", "line_no"}, [][]interface{}{{10, 1}, {11}}).
+		Build(); err == nil {
+		t.Error("expected error for a row with the wrong number of values, got nil")
+	}
+}
+
+/*
+Columns
+
+@ Return: Columns replacing the default "*", then appended to an explicit list
+*/
+func TestColumnsPostgreSQL(t *testing.T) {
+	qb := gqbd.BuildSelect(gqbd.PostgreSQL, "users")
+	includeEmail := true
+	qb.Columns("id", "name")
+	if includeEmail {
+		qb.Columns("email")
+	}
+	query, _, err := qb.Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := `SELECT "id", "name", "email" FROM "users"`; query != expected {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expected, query)
+	}
+
+	query, _, err = gqbd.BuildSelect(gqbd.PostgreSQL, "users", "id").
+		Columns("u.created_at").
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := `SELECT "id", "u"."created_at" FROM "users"`; query != expected {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expected, query)
+	}
+}
+
+/*
+Default "*" replacement / ClearColumns
+
+@ Return: Only the explicitly added columns, never "*" alongside them
+*/
+func TestClearColumnsPostgreSQL(t *testing.T) {
+	query, _, err := gqbd.BuildSelect(gqbd.PostgreSQL, "orders").
+		Aggregate("SUM", "total").
+		CountDistinct("customer_id", "customers").
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := `SELECT SUM("total"), COUNT(DISTINCT "customer_id") AS "customers" FROM "orders"`; query != expected {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expected, query)
+	}
+
+	query, _, err = gqbd.BuildSelect(gqbd.PostgreSQL, "orders", "id", "total").
+		ClearColumns().
+		Columns("customer_id").
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := `SELECT "customer_id" FROM "orders"`; query != expected {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expected, query)
+	}
+
+	query, _, err = gqbd.BuildSelect(gqbd.PostgreSQL, "orders", "id").ClearColumns().Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := `SELECT * FROM "orders"`; query != expected {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expected, query)
+	}
+}
+
+/*
+CrossJoinLateral
+
+@ Return: Lateral subquery referencing the outer table, its args numbered between select-list and WHERE args
+*/
+func TestCrossJoinLateralPostgreSQL(t *testing.T) {
+	recent := gqbd.BuildSelect(gqbd.PostgreSQL, "orders", "total", "created_at").
+		WhereRaw("orders.user_id = u.id").
+		Where("status = ?", "paid").
+		OrderBy("created_at", "DESC", nil).
+		Limit(3)
+
+	query, args, err := gqbd.BuildSelect(gqbd.PostgreSQL, "users u", "u.id", "o.total").
+		CrossJoinLateral(recent, "o").
+		Where("u.active = ?", true).
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := `SELECT "u"."id", "o"."total" FROM "users" u CROSS JOIN LATERAL ` +
+		`(SELECT "total", "created_at" FROM "orders" WHERE orders.user_id = u.id AND status = $1 ORDER BY "created_at" DESC LIMIT $2) AS "o" ` +
+		`WHERE u.active = $3`
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{"paid", 3, true}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("expected args %v, got %v", expectedArgs, args)
+	}
+
+	mysqlSub := gqbd.BuildSelect(gqbd.MariaDB, "orders", "total")
+	if _, _, err := gqbd.BuildSelect(gqbd.MariaDB, "users").CrossJoinLateral(mysqlSub, "o").Build(); err == nil {
+		t.Error("expected error for CrossJoinLateral() on MariaDB, got nil")
+	}
+}
+
+/*
+WhereDateBetween
+
+@ Return: BETWEEN with both time.Time values bound in order, and an error for a reversed range
+*/
+func TestWhereDateBetweenPostgreSQL(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 1, 31, 23, 59, 59, 0, time.UTC)
+
+	query, args, err := gqbd.BuildSelect(gqbd.PostgreSQL, "orders", "id").
+		Where("status = ?", "paid").
+		WhereDateBetween("created_at", start, end).
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := `SELECT "id" FROM "orders" WHERE status = $1 AND "created_at" BETWEEN $2 AND $3`
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{"paid", start, end}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("expected args %v, got %v", expectedArgs, args)
+	}
+
+	if _, _, err := gqbd.BuildSelect(gqbd.PostgreSQL, "orders").
+		WhereDateBetween("created_at", end, start).
+		Build(); err == nil {
+		t.Error("expected error for end before start, got nil")
+	}
+}
+
+/*
+WhereEquals
+
+@ Return: Equality conditions in sorted column order, IS NULL for nil, numbered after earlier args
+*/
+func TestWhereEqualsPostgreSQL(t *testing.T) {
+	query, args, err := gqbd.BuildSelect(gqbd.PostgreSQL, "users", "id").
+		Where("age > ?", 18).
+		WhereEquals(map[string]interface{}{
+			"status":     "active",
+			"deleted_at": nil,
+			"country":    "KR",
+		}).
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := `SELECT "id" FROM "users" WHERE age > $1 AND "country" = $2 AND "deleted_at" IS NULL AND "status" = $3`
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{18, "KR", "active"}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("expected args %v, got %v", expectedArgs, args)
+	}
+}
+
+/*
+OrderByMap
+
+@ Return: Multi-column ORDER BY in the given order with mixed directions, and an error for a disallowed column
+*/
+func TestOrderByMapPostgreSQL(t *testing.T) {
+	allowed := map[string]bool{"name": true, "created_at": true}
+
+	query, _, err := gqbd.BuildSelect(gqbd.PostgreSQL, "users", "id").
+		OrderByMap([]gqbd.OrderColumn{
+			{Column: "name", Direction: "asc"},
+			{Column: "created_at", Direction: "desc"},
+		}, allowed).
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := `SELECT "id" FROM "users" ORDER BY "name" ASC, "created_at" DESC`; query != expected {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expected, query)
+	}
+
+	_, _, err = gqbd.BuildSelect(gqbd.PostgreSQL, "users", "id").
+		OrderByMap([]gqbd.OrderColumn{{Column: "name"}, {Column: "password", Direction: "asc"}}, allowed).
+		Build()
+	if !errors.Is(err, gqbd.ErrInvalidIdentifier) || !strings.Contains(err.Error(), "password") {
+		t.Errorf("expected ErrInvalidIdentifier naming password, got %v", err)
+	}
+}
+
+/*
+Negative Limit / Offset
+
+@ Return: Error for a negative limit or offset, zero still allowed
+*/
+func TestNegativeLimitOffsetPostgreSQL(t *testing.T) {
+	if _, _, err := gqbd.BuildSelect(gqbd.PostgreSQL, "users").Limit(-1).Build(); err == nil {
+		t.Error("expected error for a negative limit, got nil")
+	}
+	if _, _, err := gqbd.BuildSelect(gqbd.PostgreSQL, "users").Limit(10).Offset(-5).Build(); err == nil {
+		t.Error("expected error for a negative offset, got nil")
+	}
+
+	query, args, err := gqbd.BuildSelect(gqbd.PostgreSQL, "users", "id").Limit(0).Offset(0).Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := `SELECT "id" FROM "users" LIMIT $1 OFFSET $2`; query != expected {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expected, query)
+	}
+	if !reflect.DeepEqual(args, []interface{}{0, 0}) {
+		t.Errorf("expected args [0 0], got %v", args)
+	}
+}
+
+/*
+SelectCoalesce
+
+@ Return: COALESCE fallbacks bound as $1, $2 ahead of the WHERE args
+*/
+func TestSelectCoalescePostgreSQL(t *testing.T) {
+	query, args, err := gqbd.BuildSelect(gqbd.PostgreSQL, "users", "id").
+		Where("active = ?", true).
+		SelectCoalesce("nickname", "anonymous", "display_name").
+		SelectCoalesce("score", 0, "").
+		Limit(5).
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := `SELECT "id", COALESCE("nickname", $1) AS "display_name", COALESCE("score", $2) FROM "users" WHERE active = $3 LIMIT $4`
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{"anonymous", 0, true, 5}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("expected args %v, got %v", expectedArgs, args)
+	}
+}
+
+/*
+WhereInSubquery
+
+@ Return: IN (subquery) with the subquery args numbered between the surrounding WHERE args
+*/
+func TestWhereInSubqueryPostgreSQL(t *testing.T) {
+	bigSpenders := gqbd.BuildSelect(gqbd.PostgreSQL, "orders", "user_id").
+		Where("total > ?", 1000).
+		Where("status = ?", "paid")
+
+	query, args, err := gqbd.BuildSelect(gqbd.PostgreSQL, "users", "id", "email").
+		Where("active = ?", true).
+		WhereInSubquery("id", bigSpenders).
+		Where("country = ?", "KR").
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := `SELECT "id", "email" FROM "users" WHERE active = $1 AND "id" IN (SELECT "user_id" FROM "orders" WHERE total > $2 AND status = $3) AND country = $4`
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{true, 1000, "paid", "KR"}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("expected args %v, got %v", expectedArgs, args)
+	}
+
+	broken := gqbd.BuildSelect(gqbd.PostgreSQL, "orders", "user_id").Having("COUNT(*) > ?", 1)
+	if _, _, err := gqbd.BuildSelect(gqbd.PostgreSQL, "users").WhereInSubquery("id", broken).Build(); err == nil {
+		t.Error("expected the subquery error to propagate, got nil")
+	}
+}
+
+/*
+WithPlaceholderStyle / PlaceholderStyle
+
+@ Return: PostgreSQL quoting with "?" placeholders everywhere, including LIMIT/OFFSET
+*/
+func TestWithPlaceholderStylePostgreSQL(t *testing.T) {
+	if style := gqbd.BuildSelect(gqbd.PostgreSQL, "users").PlaceholderStyle(); style != gqbd.PlaceholderDollar {
+		t.Errorf("expected default style %s, got %s", gqbd.PlaceholderDollar, style)
+	}
+
+	qb := gqbd.BuildSelect(gqbd.PostgreSQL, "users", "id").
+		WithPlaceholderStyle(gqbd.PlaceholderQuestion).
+		Where("age > ?", 18).
+		WhereIn("status", []interface{}{"active", "pending"}).
+		OrderBy("id", "ASC", nil).
+		Limit(10).
+		Offset(20)
+	if style := qb.PlaceholderStyle(); style != gqbd.PlaceholderQuestion {
+		t.Errorf("expected style %s, got %s", gqbd.PlaceholderQuestion, style)
+	}
+	query, args, err := qb.Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := `SELECT "id" FROM "users" WHERE age > ? AND "status" IN (?, ?) ORDER BY "id" ASC LIMIT ? OFFSET ?`
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{18, "active", "pending", 10, 20}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("expected args %v, got %v", expectedArgs, args)
+	}
+
+	if _, _, err := gqbd.BuildSelect(gqbd.PostgreSQL, "users").
+		Where("id = ?", 1).
+		WithPlaceholderStyle(gqbd.PlaceholderQuestion).
+		Build(); err == nil {
+		t.Error("expected error for WithPlaceholderStyle() after args are bound, got nil")
+	}
+	if _, _, err := gqbd.BuildSelect(gqbd.PostgreSQL, "users").WithPlaceholderStyle("percent").Build(); err == nil {
+		t.Error("expected error for an unknown placeholder style, got nil")
+	}
+}
+
+/*
+String
+
+@ Return: Built query and args for %v, the chain error for a failed builder, and an unchanged builder
+*/
+func TestStringPostgreSQL(t *testing.T) {
+	qb := gqbd.BuildSelect(gqbd.PostgreSQL, "users", "id", "name").
+		Where("age > ?", 18).
+		Where("status = ?", "active").
+		Limit(10)
+
+	expected := `SELECT "id", "name" FROM "users" WHERE age > $1 AND status = $2 LIMIT $3 -- args=[18 active 10]`
+	if got := fmt.Sprintf("%v", qb); got != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, got)
+	}
+	// Rendering must not change what Build returns
+	if got := qb.String(); got != expected {
+		t.Errorf("expected a repeat String() to match:\n%s\ngot:\n%s", expected, got)
+	}
+	if _, args, _ := qb.Build(); !reflect.DeepEqual(args, []interface{}{18, "active", 10}) {
+		t.Errorf("expected args unchanged after String(), got %v", args)
+	}
+
+	failed := gqbd.BuildSelect(gqbd.PostgreSQL, "users").Limit(-1)
+	if got := failed.String(); !strings.HasPrefix(got, "gqbd: ") || !strings.Contains(got, "negative") {
+		t.Errorf("expected the chain error, got %q", got)
+	}
+}
+
+/*
+SetCaseByKey
+
+@ Return: Three-row bulk UPDATE with WHEN/THEN pairs in key order, numbered before the IN filter
+*/
+func TestSetCaseByKeyPostgreSQL(t *testing.T) {
+	query, args, err := gqbd.BuildUpdate(gqbd.PostgreSQL, "products").
+		Set(map[string]interface{}{"updated_by": "batch"}).
+		SetCaseByKey("price", "id", map[interface{}]interface{}{10: 19.5, 2: 7.25, 3: 12.0}).
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := `UPDATE "products" SET "updated_by" = $1, ` +
+		`"price" = CASE "id" WHEN $2 THEN $3 WHEN $4 THEN $5 WHEN $6 THEN $7 ELSE "price" END ` +
+		`WHERE "id" IN ($8, $9, $10)`
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{"batch", 2, 7.25, 3, 12.0, 10, 19.5, 2, 3, 10}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("expected args %v, got %v", expectedArgs, args)
+	}
+
+	if _, _, err := gqbd.BuildSelect(gqbd.PostgreSQL, "products").
+		SetCaseByKey("price", "id", map[interface{}]interface{}{1: 2}).
+		Build(); err == nil {
+		t.Error("expected error for SetCaseByKey() on SELECT, got nil")
+	}
+}
+
+/*
+WhereNullSafeEquals
+
+@ Return: IS NOT DISTINCT FROM with the value bound, including nil
+*/
+func TestWhereNullSafeEqualsPostgreSQL(t *testing.T) {
+	query, args, err := gqbd.BuildSelect(gqbd.PostgreSQL, "users", "id").
+		Where("active = ?", true).
+		WhereNullSafeEquals("manager_id", nil).
+		WhereNullSafeEquals("team_id", 4).
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := `SELECT "id" FROM "users" WHERE active = $1 AND "manager_id" IS NOT DISTINCT FROM $2 AND "team_id" IS NOT DISTINCT FROM $3`
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{true, nil, 4}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("expected args %v, got %v", expectedArgs, args)
+	}
+}
+
+/*
+Clause ordering
+
+@ Return: JOIN, WHERE, GROUP BY, HAVING, ORDER BY, LIMIT and OFFSET in that order with args numbered to match
+*/
+func TestClauseOrderingPostgreSQL(t *testing.T) {
+	query, args, err := gqbd.BuildSelect(gqbd.PostgreSQL, "orders o", "o.customer_id").
+		SelectCoalesce("c.tier", "standard", "tier").
+		Aggregate("SUM", "o.total").
+		Offset(40).
+		Limit(20).
+		OrderBy("o.customer_id", "ASC", nil).
+		GroupBy("o.customer_id", "c.tier").
+		HavingAggregate("SUM", "o.total", ">", 500).
+		Where("o.status = ?", "paid").
+		LeftJoin("customers c", "c.id = o.customer_id").
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := `SELECT "o"."customer_id", COALESCE("c"."tier", $1) AS "tier", SUM("o"."total") FROM "orders" o ` +
+		`LEFT JOIN "customers" c ON c.id = o.customer_id ` +
+		`WHERE o.status = $2 ` +
+		`GROUP BY "o"."customer_id", "c"."tier" ` +
+		`HAVING SUM("o"."total") > $3 ` +
+		`ORDER BY "o"."customer_id" ASC ` +
+		`LIMIT $4 OFFSET $5`
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{"standard", "paid", 500, 20, 40}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("expected args %v, got %v", expectedArgs, args)
+	}
+}
+
+/*
+WhereTimeGTE / WhereTimeLT
+
+@ Return: Local times bound as the same instant in UTC, or untouched with PreserveTimeZone
+*/
+func TestWhereTimePostgreSQL(t *testing.T) {
+	seoul := time.FixedZone("KST", 9*60*60)
+	from := time.Date(2024, 5, 1, 9, 0, 0, 0, seoul)
+	to := time.Date(2024, 5, 2, 9, 0, 0, 0, seoul)
+
+	query, args, err := gqbd.BuildSelect(gqbd.PostgreSQL, "events", "id").
+		WhereTimeGTE("created_at", from).
+		WhereTimeLT("created_at", to).
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := `SELECT "id" FROM "events" WHERE "created_at" >= $1 AND "created_at" < $2`
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{
+		time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC),
+	}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("expected args %v, got %v", expectedArgs, args)
+	}
+
+	_, args, err = gqbd.BuildSelect(gqbd.PostgreSQL, "events", "id").
+		PreserveTimeZone().
+		WhereTimeGT("created_at", from).
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := args[0].(time.Time); got.Location() != seoul || !got.Equal(from) {
+		t.Errorf("expected %v kept in its location, got %v", from, got)
+	}
+}
+
+/*
+Suffix
+
+@ Return: Trusted fragment appended last with its args numbered after LIMIT
+*/
+func TestSuffixPostgreSQL(t *testing.T) {
+	query, args, err := gqbd.BuildSelect(gqbd.PostgreSQL, "jobs", "id").
+		Suffix("FOR UPDATE SKIP LOCKED").
+		Where("status = ?", "queued").
+		Limit(5).
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := `SELECT "id" FROM "jobs" WHERE status = $1 LIMIT $2 FOR UPDATE SKIP LOCKED`
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+	if !reflect.DeepEqual(args, []interface{}{"queued", 5}) {
+		t.Errorf("expected args [queued 5], got %v", args)
+	}
+
+	query, args, err = gqbd.BuildUpdate(gqbd.PostgreSQL, "jobs").
+		Set(map[string]interface{}{"status": "running"}).
+		Where("id = ?", 9).
+		Suffix("RETURNING id, ? AS worker", "worker-1").
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery = `UPDATE "jobs" SET "status" = $1 WHERE id = $2 RETURNING id, $3 AS worker`
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{"running", 9, "worker-1"}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("expected args %v, got %v", expectedArgs, args)
+	}
+}
+
+/*
+Prefix
+
+@ Return: Tag comment written first, prefix args numbered $1 and the statement shifted after them
+*/
+func TestPrefixPostgreSQL(t *testing.T) {
+	query, args, err := gqbd.BuildSelect(gqbd.PostgreSQL, "orders", "id").
+		Where("status = ?", "paid").
+		Limit(10).
+		Prefix("/* service:orders */").
+		Prefix("WITH params AS (SELECT ?::int AS min_total)", 100).
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := `/* service:orders */ WITH params AS (SELECT $1::int AS min_total) SELECT "id" FROM "orders" WHERE status = $2 LIMIT $3`
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{100, "paid", 10}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("expected args %v, got %v", expectedArgs, args)
+	}
+
+	query, _, err = gqbd.BuildSelect(gqbd.PostgreSQL, "orders", "id").Explain().Prefix("/* debug */").Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := `/* debug */ EXPLAIN SELECT "id" FROM "orders"`; query != expected {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expected, query)
+	}
+}
+
+/*
+InsertIgnore
+
+@ Return: INSERT ... ON CONFLICT DO NOTHING before RETURNING, and an error for Redshift
+*/
+func TestInsertIgnorePostgreSQL(t *testing.T) {
+	query, args, err := gqbd.BuildInsert(gqbd.PostgreSQL, "users").
+		Values(map[string]interface{}{"email": "ann@example.com", "name": "Ann"}).
+		InsertIgnore().
+		Returning("id").
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := `INSERT INTO "users" ("email", "name") VALUES ($1, $2) ON CONFLICT DO NOTHING RETURNING id`
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{"ann@example.com", "Ann"}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("expected args %v, got %v", expectedArgs, args)
+	}
+
+	if _, _, err := gqbd.BuildInsert(gqbd.Redshift, "users").
+		Values(map[string]interface{}{"email": "ann@example.com"}).
+		InsertIgnore().
+		Build(); err == nil {
+		t.Error("expected error for InsertIgnore() on Redshift, got nil")
+	}
+}
+
+/*
+BuildReplace
+
+@ Return: Error since PostgreSQL has no REPLACE INTO
+*/
+func TestBuildReplacePostgreSQL(t *testing.T) {
+	if _, _, err := gqbd.BuildReplace(gqbd.PostgreSQL, "users").
+		Values(map[string]interface{}{"id": 1}).
+		Build(); err == nil {
+		t.Error("expected error for BuildReplace() on PostgreSQL, got nil")
+	}
+}
+
+/*
+EscapeIdentifierLiteral
+
+@ Return: Whole name quoted as one identifier, where EscapeIdentifier splits on dots
+*/
+func TestEscapeIdentifierLiteralPostgreSQL(t *testing.T) {
+	split, err := gqbd.EscapeIdentifier(gqbd.PostgreSQL, "order.total")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := `"order"."total"`; split != expected {
+		t.Errorf("expected %s, got %s", expected, split)
+	}
+
+	literal, err := gqbd.EscapeIdentifierLiteral(gqbd.PostgreSQL, "order.total")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := `"order.total"`; literal != expected {
+		t.Errorf("expected %s, got %s", expected, literal)
+	}
+
+	literal, err = gqbd.EscapeIdentifierLiteral(gqbd.PostgreSQL, `legacy "v1".name`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := `"legacy ""v1"".name"`; literal != expected {
+		t.Errorf("expected %s, got %s", expected, literal)
+	}
+
+	if _, err := gqbd.EscapeIdentifierLiteral(gqbd.PostgreSQL, ""); !errors.Is(err, gqbd.ErrInvalidIdentifier) {
+		t.Errorf("expected ErrInvalidIdentifier for an empty name, got %v", err)
+	}
+}
+
+/*
+Empty Values / Set
+
+@ Return: ErrNoData for an empty data map on INSERT and UPDATE
+*/
+func TestEmptyDataPostgreSQL(t *testing.T) {
+	_, _, err := gqbd.BuildInsert(gqbd.PostgreSQL, "users").Values(map[string]interface{}{}).Build()
+	if !errors.Is(err, gqbd.ErrNoData) || !strings.Contains(err.Error(), "Values() called with empty data") {
+		t.Errorf("expected ErrNoData from Values(), got %v", err)
+	}
+
+	_, _, err = gqbd.BuildUpdate(gqbd.PostgreSQL, "users").
+		Set(map[string]interface{}{}).
+		Where("id = ?", 1).
+		Build()
+	if !errors.Is(err, gqbd.ErrNoData) || !strings.Contains(err.Error(), "Set() called with empty data") {
+		t.Errorf("expected ErrNoData from Set(), got %v", err)
+	}
+}
+
+// tenantFilter scopes a query to one tenant.
+type tenantFilter struct {
+	tenantID int
+}
+
+func (f tenantFilter) Apply(qb *gqbd.QueryBuilder) *gqbd.QueryBuilder {
+	return qb.Where("tenant_id = ?", f.tenantID)
+}
+
+/*
+ApplyFilters
+
+@ Return: Conditions and args from each filter merged in order, and filter errors propagated
+*/
+func TestApplyFiltersPostgreSQL(t *testing.T) {
+	notDeleted := gqbd.FilterFunc(func(qb *gqbd.QueryBuilder) *gqbd.QueryBuilder {
+		return qb.WhereRaw("deleted_at IS NULL")
+	})
+	createdAfter := gqbd.FilterFunc(func(qb *gqbd.QueryBuilder) *gqbd.QueryBuilder {
+		return qb.Where("created_at > ?", "2024-01-01")
+	})
+
+	query, args, err := gqbd.BuildSelect(gqbd.PostgreSQL, "projects", "id").
+		Where("status = ?", "active").
+		ApplyFilters(tenantFilter{tenantID: 7}, notDeleted, nil, createdAfter).
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := `SELECT "id" FROM "projects" WHERE status = $1 AND tenant_id = $2 AND deleted_at IS NULL AND created_at > $3`
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{"active", 7, "2024-01-01"}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("expected args %v, got %v", expectedArgs, args)
+	}
+
+	invalid := gqbd.FilterFunc(func(qb *gqbd.QueryBuilder) *gqbd.QueryBuilder {
+		return qb.WhereIn("", []interface{}{1})
+	})
+	if _, _, err := gqbd.BuildSelect(gqbd.PostgreSQL, "projects").
+		ApplyFilters(invalid, tenantFilter{tenantID: 7}).
+		Build(); !errors.Is(err, gqbd.ErrInvalidIdentifier) {
+		t.Errorf("expected the filter error to propagate, got %v", err)
+	}
+}
+
+/*
+WithoutSoftDeleted / OnlySoftDeleted
+
+@ Return: IS NULL / IS NOT NULL on the escaped column, ANDed with the other conditions
+*/
+func TestSoftDeletePostgreSQL(t *testing.T) {
+	query, args, err := gqbd.BuildSelect(gqbd.PostgreSQL, "users u", "u.id").
+		Where("u.active = ?", true).
+		WithoutSoftDeleted("u.deleted_at").
+		Where("u.age > ?", 18).
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := `SELECT "u"."id" FROM "users" u WHERE u.active = $1 AND "u"."deleted_at" IS NULL AND u.age > $2`
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{true, 18}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("expected args %v, got %v", expectedArgs, args)
+	}
+
+	query, _, err = gqbd.BuildSelect(gqbd.PostgreSQL, "users", "id").OnlySoftDeleted("deleted_at").Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := `SELECT "id" FROM "users" WHERE "deleted_at" IS NOT NULL`; query != expected {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expected, query)
+	}
+}
+
+/*
+When with a conditional join
+
+@ Return: Join args numbered ahead of WHERE args when the join is added, and unchanged numbering when skipped
+*/
+func TestWhenJoinPostgreSQL(t *testing.T) {
+	build := func(tags []interface{}) (string, []interface{}, error) {
+		return gqbd.BuildSelect(gqbd.PostgreSQL, "articles a", "a.id").
+			Where("a.published = ?", true).
+			When(len(tags) > 0, func(qb *gqbd.QueryBuilder) *gqbd.QueryBuilder {
+				rows := make([][]interface{}, len(tags))
+				for i, tag := range tags {
+					rows[i] = []interface{}{tag}
+				}
+				return qb.JoinValues(rows, "t", []string{"name"}, "t.name = a.tag")
+			}).
+			Where("a.author_id = ?", 3).
+			Build()
+	}
+
+	query, args, err := build([]interface{}{"go", "sql"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := `SELECT "a"."id" FROM "articles" a JOIN (VALUES ($1), ($2)) AS "t"("name") ON t.name = a.tag ` +
+		`WHERE a.published = $3 AND a.author_id = $4`
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{"go", "sql", true, 3}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("expected args %v, got %v", expectedArgs, args)
+	}
+
+	query, args, err = build(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery = `SELECT "a"."id" FROM "articles" a WHERE a.published = $1 AND a.author_id = $2`
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+	expectedArgs = []interface{}{true, 3}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("expected args %v, got %v", expectedArgs, args)
+	}
+}
+
+/*
+WhereInCSV
+
+@ Return: IN clause from typical and whitespace-padded CSV, and 1 = 0 for an empty one
+*/
+func TestWhereInCSVPostgreSQL(t *testing.T) {
+	query, args, err := gqbd.BuildSelect(gqbd.PostgreSQL, "users", "id").
+		Where("active = ?", true).
+		WhereInCSV("id", "1,2,3").
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := `SELECT "id" FROM "users" WHERE active = $1 AND "id" IN ($2, $3, $4)`; query != expected {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expected, query)
+	}
+	if expected := []interface{}{true, "1", "2", "3"}; !reflect.DeepEqual(args, expected) {
+		t.Errorf("expected args %v, got %v", expected, args)
+	}
+
+	_, args, err = gqbd.BuildSelect(gqbd.PostgreSQL, "users", "id").WhereInCSV("id", " 4 , ,5,  ").Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := []interface{}{"4", "5"}; !reflect.DeepEqual(args, expected) {
+		t.Errorf("expected args %v, got %v", expected, args)
+	}
+
+	query, args, err = gqbd.BuildSelect(gqbd.PostgreSQL, "users", "id").
+		WhereInCSV("id", " , ").
+		Where("age > ?", 18).
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := `SELECT "id" FROM "users" WHERE 1 = 0 AND age > $1`; query != expected {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expected, query)
+	}
+	if expected := []interface{}{18}; !reflect.DeepEqual(args, expected) {
+		t.Errorf("expected args %v, got %v", expected, args)
+	}
+}
+
+/*
+Operation, Table and Args
+
+@ Return: Builder state read back, with Args matching Build's args across every arg group
+*/
+func TestStateAccessorsPostgreSQL(t *testing.T) {
+	qb := gqbd.BuildSelect(gqbd.PostgreSQL, "public.users u", "u.id").
+		SelectCoalesce("u.nickname", "anon", "nickname").
+		CrossJoinLateral(gqbd.BuildSelect(gqbd.PostgreSQL, "orders", "id").Where("user_id = u.id AND total > ?", 100), "o").
+		Where("u.age > ?", 18).
+		WhereIn("u.status", []interface{}{"active", "pending"}).
+		GroupBy("u.id", "u.nickname").
+		Having("COUNT(o.id) > ?", 2).
+		Limit(10)
+	if qb.Operation() != "SELECT" {
+		t.Errorf("expected operation SELECT, got %s", qb.Operation())
+	}
+	if qb.Table() != "public.users u" {
+		t.Errorf("expected table public.users u, got %s", qb.Table())
+	}
+	_, built, err := qb.Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedArgs := []interface{}{"anon", 100, 18, "active", "pending", 2, 10}
+	args := qb.Args()
+	if !reflect.DeepEqual(args, expectedArgs) || !reflect.DeepEqual(args, built) {
+		t.Errorf("expected args %v matching Build, got %v", expectedArgs, args)
+	}
+
+	args[0] = "changed"
+	if !reflect.DeepEqual(qb.Args(), expectedArgs) {
+		t.Errorf("expected Args() to return a copy, got %v", qb.Args())
+	}
+
+	del := gqbd.BuildDelete(gqbd.PostgreSQL, "sessions")
+	if del.Operation() != "DELETE" || del.Table() != "sessions" || len(del.Args()) != 0 {
+		t.Errorf("unexpected state: %s %s %v", del.Operation(), del.Table(), del.Args())
+	}
+	if args := gqbd.BuildSelect(gqbd.PostgreSQL, "users").Limit(-1).Args(); args != nil {
+		t.Errorf("expected nil args for a builder with an error, got %v", args)
+	}
+}
+
+/*
+ForUpdateOf
+
+@ Return: FOR UPDATE OF list with escaped tables after pagination, and errors for other dialects and qualified names
+*/
+func TestForUpdateOfPostgreSQL(t *testing.T) {
+	query, args, err := gqbd.BuildSelect(gqbd.PostgreSQL, "orders o", "o.id").
+		InnerJoin("customers c", "o.customer_id = c.id").
+		Where("o.status = ?", "pending").
+		Limit(10).
+		ForUpdateOf("o").
+		ForUpdateOf("customers").
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := `SELECT "o"."id" FROM "orders" o INNER JOIN "customers" c ON o.customer_id = c.id WHERE o.status = $1 LIMIT $2 FOR UPDATE OF "o", "customers"`
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+	if expected := []interface{}{"pending", 10}; !reflect.DeepEqual(args, expected) {
+		t.Errorf("expected args %v, got %v", expected, args)
+	}
+
+	query, _, err = gqbd.BuildSelect(gqbd.Mysql, "orders", "id").ForUpdateOf("orders").Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := "SELECT `id` FROM `orders` FOR UPDATE OF `orders`"; query != expected {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expected, query)
+	}
+
+	for _, qb := range []*gqbd.QueryBuilder{
+		gqbd.BuildSelect(gqbd.MariaDB, "orders", "id").ForUpdateOf("orders"),
+		gqbd.BuildSelect(gqbd.SQLite, "orders", "id").ForUpdateOf("orders"),
+		gqbd.BuildSelect(gqbd.PostgreSQL, "orders", "id").ForUpdateOf(),
+		gqbd.BuildSelect(gqbd.PostgreSQL, "orders", "id").ForUpdateOf("orders; DROP"),
+		gqbd.BuildDelete(gqbd.PostgreSQL, "orders").ForUpdateOf("orders"),
+	} {
+		if _, _, err := qb.Build(); err == nil {
+			t.Error("expected error, got nil")
+		}
+	}
+
+	_, _, err = gqbd.BuildSelect(gqbd.PostgreSQL, "app.users", "id").ForUpdateOf("app.users").Build()
+	if !errors.Is(err, gqbd.ErrInvalidIdentifier) {
+		t.Errorf("expected ErrInvalidIdentifier for a schema-qualified lock table, got %v", err)
+	}
+}
+
+/*
+WithTerminator
+
+@ Return: Trailing semicolon only when enabled, and none inside embedded subqueries
+*/
+func TestWithTerminatorPostgreSQL(t *testing.T) {
+	query, _, err := gqbd.BuildSelect(gqbd.PostgreSQL, "users", "id").Where("id = ?", 1).Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := `SELECT "id" FROM "users" WHERE id = $1`; query != expected {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expected, query)
+	}
+
+	query, args, err := gqbd.BuildSelect(gqbd.PostgreSQL, "users", "id").
+		Where("id = ?", 1).
+		Limit(5).
+		WithTerminator().
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := `SELECT "id" FROM "users" WHERE id = $1 LIMIT $2;`; query != expected {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expected, query)
+	}
+	if expected := []interface{}{1, 5}; !reflect.DeepEqual(args, expected) {
+		t.Errorf("expected args %v, got %v", expected, args)
+	}
+
+	sub := gqbd.BuildSelect(gqbd.PostgreSQL, "orders", "user_id").Where("total > ?", 100).WithTerminator()
+	query, _, err = gqbd.BuildSelect(gqbd.PostgreSQL, "users", "id").WhereInSubquery("id", sub).WithTerminator().Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := `SELECT "id" FROM "users" WHERE "id" IN (SELECT "user_id" FROM "orders" WHERE total > $1);`; query != expected {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expected, query)
+	}
+
+	_, count, err := gqbd.BuildSelect(gqbd.PostgreSQL, "users", "status").GroupBy("status").WithTerminator().BuildWithCount()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := `SELECT COUNT(*) FROM (SELECT "status" FROM "users" GROUP BY "status") AS count_query;`; count.Query != expected {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expected, count.Query)
+	}
+}
+
+/*
+WithPlaceholderStyle with colon placeholders
+
+@ Return: Shifted :N placeholders with quoted text and array slice bounds left as written,
+and ErrPlaceholderMismatch for a subquery of another style
+*/
+func TestWithPlaceholderStyleColonPostgreSQL(t *testing.T) {
+	query, args, err := gqbd.BuildSelect(gqbd.PostgreSQL, "events").
+		WithPlaceholderStyle(gqbd.PlaceholderColon).
+		SelectCoalesce("a", 0, "a").
+		WhereRaw(`ts::time > '10:30' AND "note:1" = ? AND kind = ?`, "x", "y").
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := `SELECT COALESCE("a", :1) AS "a" FROM "events" WHERE ts::time > '10:30' AND "note:1" = :2 AND kind = :3`
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{0, "x", "y"}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("expected args %v, got %v", expectedArgs, args)
+	}
+
+	query, _, err = gqbd.BuildSelect(gqbd.PostgreSQL, "events").
+		WithPlaceholderStyle(gqbd.PlaceholderColon).
+		SelectCoalesce("a", 0, "a").
+		WhereRaw("tags[1:2] = ? AND tags[:2] <> ?", "x", "y").
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery = `SELECT COALESCE("a", :1) AS "a" FROM "events" WHERE tags[1:2] = :2 AND tags[:2] <> :3`
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+
+	sub := gqbd.BuildSelect(gqbd.PostgreSQL, "orders", "user_id").Where("total > ?", 100)
+	_, _, err = gqbd.BuildSelect(gqbd.PostgreSQL, "users").
+		WithPlaceholderStyle(gqbd.PlaceholderColon).
+		WhereInSubquery("id", sub).
+		Build()
+	if !errors.Is(err, gqbd.ErrPlaceholderMismatch) {
+		t.Errorf("expected ErrPlaceholderMismatch, got %v", err)
+	}
+}
+
+/*
+BuildWithCount with Explain
+
+@ Return: EXPLAIN on the page query only, never inside or on the count query
+*/
+func TestBuildWithCountExplainPostgreSQL(t *testing.T) {
+	page, count, err := gqbd.BuildSelect(gqbd.PostgreSQL, "users", "status").
+		Where("age > ?", 18).
+		GroupBy("status").
+		Explain().
+		BuildWithCount()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := `EXPLAIN SELECT "status" FROM "users" WHERE age > $1 GROUP BY "status"`; page.Query != expected {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expected, page.Query)
+	}
+	if expected := `SELECT COUNT(*) FROM (SELECT "status" FROM "users" WHERE age > $1 GROUP BY "status") AS count_query`; count.Query != expected {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expected, count.Query)
+	}
+	if expected := []interface{}{18}; !reflect.DeepEqual(count.Args, expected) {
+		t.Errorf("expected args %v, got %v", expected, count.Args)
+	}
+
+	_, count, err = gqbd.BuildSelect(gqbd.PostgreSQL, "users", "id").Explain().BuildWithCount()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := `SELECT COUNT(*) FROM "users"`; count.Query != expected {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expected, count.Query)
+	}
+}
+
+/*
+StrictGroupBy with window and COALESCE columns
+
+@ Return: Ungrouped SELECT accepted, since window functions and COALESCE are not aggregates
+*/
+func TestStrictGroupByExpressionsPostgreSQL(t *testing.T) {
+	query, _, err := gqbd.BuildSelect(gqbd.PostgreSQL, "t", "id").
+		SelectWindow("ROW_NUMBER", "rn", nil, "id").
+		StrictGroupBy().
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := `SELECT "id", ROW_NUMBER() OVER (ORDER BY "id" ASC) AS "rn" FROM "t"`; query != expected {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expected, query)
+	}
+
+	if _, _, err := gqbd.BuildSelect(gqbd.PostgreSQL, "t", "id").
+		SelectCoalesce("nickname", "", "name").
+		StrictGroupBy().
+		Build(); err != nil {
+		t.Errorf("unexpected error for COALESCE: %v", err)
+	}
+
+	if _, _, err := gqbd.BuildSelect(gqbd.PostgreSQL, "t", "id").
+		SelectWindow("SUM(amount)", "running", nil, "id").
+		Aggregate("MAX", "amount").
+		StrictGroupBy().
+		Build(); err == nil {
+		t.Error("expected error for a plain column next to a real aggregate, got nil")
+	}
+}
+
+/*
+PushDownLimit with Limit(0)
+
+@ Return: Explicit zero limit pushed into the driving-table subquery like any other limit
+*/
+func TestPushDownLimitZeroPostgreSQL(t *testing.T) {
+	query, args, err := gqbd.BuildSelect(gqbd.PostgreSQL, "orders o", "o.id").
+		LeftJoin("customers c", "c.id = o.customer_id").
+		Limit(0).
+		PushDownLimit().
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := `SELECT "o"."id" FROM (SELECT * FROM "orders" o LIMIT $1) AS o LEFT JOIN "customers" c ON c.id = o.customer_id`
+	if query != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, query)
+	}
+	if expected := []interface{}{0}; !reflect.DeepEqual(args, expected) {
+		t.Errorf("expected args %v, got %v", expected, args)
+	}
+}
@@ -6,160 +6,99 @@ import (
 	"strings"
 )
 
-func (qb *QueryBuilder) buildPostgreSQLSelect() (string, []interface{}, error) {
-	var queryBuilder strings.Builder
-	queryBuilder.WriteString("SELECT ")
-	if qb.distinct {
-		queryBuilder.WriteString("DISTINCT ")
-	}
-	queryBuilder.WriteString(strings.Join(qb.columns, ", "))
-	queryBuilder.WriteString(" FROM ")
-	queryBuilder.WriteString(qb.table)
-	if len(qb.joins) > 0 {
-		queryBuilder.WriteString(" " + strings.Join(qb.joins, " "))
-	}
-	if len(qb.conditions) > 0 {
-		queryBuilder.WriteString(" WHERE " + strings.Join(qb.conditions, " AND "))
-	}
-	if len(qb.groupBy) > 0 {
-		queryBuilder.WriteString(" GROUP BY " + strings.Join(qb.groupBy, ", "))
-	}
-	if len(qb.having) > 0 {
-		queryBuilder.WriteString(" HAVING " + strings.Join(qb.having, " AND "))
-	}
-	if qb.orderBy != "" {
-		queryBuilder.WriteString(" ORDER BY " + qb.orderBy)
-	}
-	if qb.limit > 0 {
-		placeholder := fmt.Sprintf("$%d", len(qb.args)+1)
-		queryBuilder.WriteString(" LIMIT " + placeholder)
-		qb.args = append(qb.args, qb.limit)
-	}
-	if qb.offset > 0 {
-		placeholder := fmt.Sprintf("$%d", len(qb.args)+1)
-		queryBuilder.WriteString(" OFFSET " + placeholder)
-		qb.args = append(qb.args, qb.offset)
-	}
-	return queryBuilder.String(), qb.args, nil
+// postgresDialect renders PostgreSQL: double-quoted identifiers, $N placeholders
+// and LIMIT/OFFSET pagination.
+type postgresDialect struct{}
+
+func (postgresDialect) EscapeIdentifier(name string) (string, error) {
+	return escapePostgreSQLIdentifier(name)
 }
 
-func (qb *QueryBuilder) buildPostgreSQLInsert() (string, []interface{}, error) {
-	if qb.data == nil {
-		return "", nil, fmt.Errorf("no data provided for INSERT")
-	}
-	var cols []string
-	var placeholders []string
-	var args []interface{}
+func (postgresDialect) Placeholder(index int) string {
+	return fmt.Sprintf("$%d", index)
+}
 
-	for col, val := range qb.data {
-		safeCol, err := EscapeIdentifier(qb.dbType, col)
-		if err != nil {
-			return "", nil, err
-		}
-		cols = append(cols, safeCol)
-		placeholders = append(placeholders, "?")
-		args = append(args, val)
+func (d postgresDialect) LimitOffset(limit, offset, nextIndex int, ordered bool) (string, []interface{}, error) {
+	var clause strings.Builder
+	var args []interface{}
+	if limit >= 0 {
+		clause.WriteString(" LIMIT " + d.Placeholder(nextIndex+len(args)))
+		args = append(args, limit)
+	}
+	if offset >= 0 {
+		clause.WriteString(" OFFSET " + d.Placeholder(nextIndex+len(args)))
+		args = append(args, offset)
 	}
+	return clause.String(), args, nil
+}
 
-	placeholdersStr := strings.Join(placeholders, ", ")
-	placeholdersStr = ReplacePlaceholders(qb.dbType, placeholdersStr, 1)
+func (postgresDialect) SupportsReturning() bool {
+	return true
+}
 
-	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", qb.table, strings.Join(cols, ", "), placeholdersStr)
-	if qb.returning != "" {
-		query += " RETURNING " + qb.returning
-	}
+// redshiftDialect is PostgreSQL without INSERT ... RETURNING.
+type redshiftDialect struct {
+	postgresDialect
+}
 
-	return query, args, nil
+func (redshiftDialect) SupportsReturning() bool {
+	return false
 }
 
-func (qb *QueryBuilder) buildPostgreSQLUpdate() (string, []interface{}, error) {
-	if qb.data == nil {
-		return "", nil, fmt.Errorf("no data provided for UPDATE")
+func (qb *QueryBuilder) buildPostgreSQLUpdateFromValues() (string, []interface{}, error) {
+	// Key column first, remaining columns sorted for deterministic output
+	keys := []string{qb.valuesKey}
+	var setKeys []string
+	for key := range qb.valuesRows[0] {
+		if key != qb.valuesKey {
+			setKeys = append(setKeys, key)
+		}
 	}
-	var setClauses []string
-	var updateArgs []interface{}
+	sort.Strings(setKeys)
+	keys = append(keys, setKeys...)
 
-	var keys []string
-	for key := range qb.data {
-		keys = append(keys, key)
-	}
-	
-	sort.Strings(keys)
-	for _, key := range keys {
+	safeKeys := make([]string, len(keys))
+	for i, key := range keys {
 		safeCol, err := EscapeIdentifier(qb.dbType, key)
 		if err != nil {
 			return "", nil, err
 		}
-		setClauses = append(setClauses, fmt.Sprintf("%s = ?", safeCol))
-		updateArgs = append(updateArgs, qb.data[key])
+		safeKeys[i] = safeCol
 	}
 
-	setClausesStr := strings.Join(setClauses, ", ")
-	setClausesStr = ReplacePlaceholders(qb.dbType, setClausesStr, 1)
+	// Refer to the target table by its alias when one was given
+	tableRef := qb.table
+	if parts := strings.Fields(qb.table); len(parts) > 1 {
+		tableRef = parts[len(parts)-1]
+	}
 
-	query := fmt.Sprintf("UPDATE %s SET %s", qb.table, setClausesStr)
+	var setClauses []string
+	for _, safeCol := range safeKeys[1:] {
+		setClauses = append(setClauses, fmt.Sprintf("%s = v.%s", safeCol, safeCol))
+	}
 
-	allArgs := updateArgs
-	if len(qb.conditions) > 0 {
-		whereConditions := make([]string, len(qb.conditions))
-		currentOffset := len(updateArgs)
-		for i, condition := range qb.conditions {
-			whereConditions[i] = shiftPostgreSQLPlaceholders(condition, currentOffset)
-			// Count the placeholders in this condition to update offset
-			currentOffset += countPlaceholders(condition)
+	var valueRows []string
+	var allArgs []interface{}
+	for _, row := range qb.valuesRows {
+		placeholders := generatePlaceholders(qb.dialect, len(allArgs)+1, len(keys))
+		valueRows = append(valueRows, "("+placeholders+")")
+		for _, key := range keys {
+			allArgs = append(allArgs, row[key])
 		}
-		query += " WHERE " + strings.Join(whereConditions, " AND ")
-		allArgs = append(allArgs, qb.args...)
 	}
 
-	return query, allArgs, nil
-}
+	query := fmt.Sprintf("UPDATE %s SET %s FROM (VALUES %s) AS v(%s) WHERE %s.%s = v.%s",
+		qb.table, strings.Join(setClauses, ", "), strings.Join(valueRows, ", "),
+		strings.Join(safeKeys, ", "), tableRef, safeKeys[0], safeKeys[0])
 
-func countPlaceholders(condition string) int {
-	count := 0
-	i := 0
-	for i < len(condition) {
-		if condition[i] == '$' && i+1 < len(condition) {
-			j := i + 1
-			for j < len(condition) && condition[j] >= '0' && condition[j] <= '9' {
-				j++
-			}
-			if j > i+1 {
-				count++
-				i = j
-				continue
-			}
-		}
-		i++
+	if len(qb.conditions) > 0 {
+		query += " AND " + strings.Join(qb.offsetPlaceholders(qb.conditions, len(allArgs)), " AND ")
+		allArgs = append(allArgs, qb.args...)
 	}
-	return count
-}
 
-func shiftPostgreSQLPlaceholders(condition string, offset int) string {
-	var result strings.Builder
-	i := 0
-	placeholderIndex := 1
-	for i < len(condition) {
-		if condition[i] == '$' && i+1 < len(condition) {
-			// Find the end of the placeholder number
-			j := i + 1
-			for j < len(condition) && condition[j] >= '0' && condition[j] <= '9' {
-				j++
-			}
-			if j > i+1 {
-				// Extract and shift the placeholder number
-				result.WriteString(fmt.Sprintf("$%d", offset+placeholderIndex))
-				placeholderIndex++
-				i = j
-				continue
-			}
-		}
-		result.WriteByte(condition[i])
-		i++
-	}
-	return result.String()
+	return query, allArgs, nil
 }
 
 func escapePostgreSQLIdentifier(name string) (string, error) {
-	return `"` + name + `"`, nil
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`, nil
 }
\ No newline at end of file
@@ -0,0 +1,54 @@
+package gqbd_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/donghquinn/gqbd"
+)
+
+/*
+BuildSelect
+
+@ Return: Final SELECT query string, arguments slice, and error if any
+*/
+func TestBuildSelectRedshift(t *testing.T) {
+	qb := gqbd.BuildSelect(gqbd.Redshift, "table_name", "col1").
+		WhereIn("col2", []interface{}{1, 2}).
+		Where("col1 = ?", 100).
+		Limit(10)
+
+	query, args, err := qb.Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := "SELECT \"col1\" FROM \"table_name\" WHERE \"col2\" IN ($1, $2) AND col1 = $3 LIMIT $4"
+	normalizedQuery := strings.Join(strings.Fields(query), " ")
+	if normalizedQuery != expectedQuery {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expectedQuery, normalizedQuery)
+	}
+	expectedArgs := []interface{}{1, 2, 100, 10}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("expected args %v, got %v", expectedArgs, args)
+	}
+}
+
+/*
+BuildInsert with Returning
+
+@ Return: Error because Redshift has no RETURNING clause
+*/
+func TestBuildInsertReturningRedshift(t *testing.T) {
+	qb := gqbd.BuildInsert(gqbd.Redshift, "table_name").
+		Values(map[string]interface{}{"col1": 200}).
+		Returning("col1")
+
+	_, _, err := qb.Build()
+	if err == nil {
+		t.Fatal("expected error for RETURNING on Redshift, got nil")
+	}
+	if !strings.Contains(err.Error(), "Redshift") {
+		t.Errorf("expected error to mention Redshift, got %v", err)
+	}
+}
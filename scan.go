@@ -0,0 +1,115 @@
+package gqbd
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+)
+
+/*
+ScanStruct
+
+@ rows: Rows positioned on a row by rows.Next()
+@ dest: Pointer to a struct whose `db:"column"` tagged fields receive the row
+@ Return: Error from scanning, if any
+
+Columns are matched to fields by the same `db` tags ValuesStruct uses. Columns
+without a matching field are discarded. NULLs scan into pointer fields as nil and
+into sql.Null* fields as invalid values; a NULL into a plain field is an error.
+*/
+func ScanStruct(rows *sql.Rows, dest interface{}) error {
+	rv := reflect.ValueOf(dest)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("expected a non-nil pointer to a struct, got %T", dest)
+	}
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	return scanRow(rows, columns, structFieldIndexes(rv.Elem().Type()), rv.Elem())
+}
+
+/*
+ScanStructs
+
+@ rows: Rows to read; every remaining row is scanned and rows is closed
+@ dest: Pointer to a slice of structs or struct pointers (e.g. *[]User or *[]*User)
+@ Return: Error from scanning or iterating rows, if any
+*/
+func ScanStructs(rows *sql.Rows, dest interface{}) error {
+	defer rows.Close()
+	rv := reflect.ValueOf(dest)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("expected a non-nil pointer to a slice, got %T", dest)
+	}
+	slice := rv.Elem()
+	elemType := slice.Type().Elem()
+	isPtr := elemType.Kind() == reflect.Ptr
+	structType := elemType
+	if isPtr {
+		structType = elemType.Elem()
+	}
+	if structType.Kind() != reflect.Struct {
+		return fmt.Errorf("expected a slice of structs, got %T", dest)
+	}
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	indexes := structFieldIndexes(structType)
+	for rows.Next() {
+		item := reflect.New(structType)
+		if err := scanRow(rows, columns, indexes, item.Elem()); err != nil {
+			return err
+		}
+		if isPtr {
+			slice.Set(reflect.Append(slice, item))
+		} else {
+			slice.Set(reflect.Append(slice, item.Elem()))
+		}
+	}
+	return rows.Err()
+}
+
+// scanRow scans the current row into the fields of target named by indexes.
+func scanRow(rows *sql.Rows, columns []string, indexes map[string][]int, target reflect.Value) error {
+	targets := make([]interface{}, len(columns))
+	for i, column := range columns {
+		index, ok := indexes[column]
+		if !ok {
+			var discard interface{}
+			targets[i] = &discard
+			continue
+		}
+		targets[i] = target.FieldByIndex(index).Addr().Interface()
+	}
+	return rows.Scan(targets...)
+}
+
+// structFieldIndexes maps each `db` tagged column of t to its field index path,
+// flattening untagged embedded structs the same way collectStructFields does.
+func structFieldIndexes(t reflect.Type) map[string][]int {
+	indexes := make(map[string][]int)
+	collectFieldIndexes(t, nil, indexes)
+	return indexes
+}
+
+func collectFieldIndexes(t reflect.Type, parent []int, indexes map[string][]int) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		index := append(append([]int(nil), parent...), i)
+		tag, hasTag := field.Tag.Lookup("db")
+		if field.Anonymous && !hasTag && field.Type.Kind() == reflect.Struct {
+			collectFieldIndexes(field.Type, index, indexes)
+			continue
+		}
+		if field.PkgPath != "" || !hasTag || tag == "-" {
+			continue
+		}
+		name, _ := parseStructTag(tag)
+		if name == "" {
+			continue
+		}
+		indexes[name] = index
+	}
+}
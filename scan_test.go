@@ -0,0 +1,173 @@
+package gqbd_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/donghquinn/gqbd"
+)
+
+// fakeRowsDriver serves fixed result sets keyed by DSN so scanning can be tested
+// without a database.
+type fakeRowsDriver struct{}
+
+type fakeResultSet struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+var fakeResultSets = map[string]fakeResultSet{}
+
+func (fakeRowsDriver) Open(dsn string) (driver.Conn, error) {
+	set, ok := fakeResultSets[dsn]
+	if !ok {
+		return nil, errors.New("unknown fake result set: " + dsn)
+	}
+	return &fakeConn{set: set}, nil
+}
+
+type fakeConn struct {
+	set fakeResultSet
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("prepare not supported")
+}
+
+func (c *fakeConn) Close() error { return nil }
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("transactions not supported")
+}
+
+func (c *fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	return &fakeRows{set: c.set}, nil
+}
+
+type fakeRows struct {
+	set  fakeResultSet
+	next int
+}
+
+func (r *fakeRows) Columns() []string { return r.set.columns }
+
+func (r *fakeRows) Close() error { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.next >= len(r.set.values) {
+		return io.EOF
+	}
+	copy(dest, r.set.values[r.next])
+	r.next++
+	return nil
+}
+
+func init() {
+	sql.Register("gqbdfake", fakeRowsDriver{})
+}
+
+type scannedAudit struct {
+	CreatedBy string `db:"created_by"`
+}
+
+type scannedUser struct {
+	scannedAudit
+	ID       int64          `db:"id"`
+	Name     string         `db:"name"`
+	Nickname *string        `db:"nickname"`
+	Email    sql.NullString `db:"email"`
+	Ignored  string         `db:"-"`
+}
+
+func queryFakeRows(t *testing.T, dsn string, set fakeResultSet) *sql.Rows {
+	t.Helper()
+	fakeResultSets[dsn] = set
+	db, err := sql.Open("gqbdfake", dsn)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	query, args, err := gqbd.BuildSelect(gqbd.PostgreSQL, "users").Where("id > ?", 0).Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return rows
+}
+
+/*
+ScanStruct
+
+@ Return: Current row scanned into tagged fields, NULLs into pointer and sql.Null* fields
+*/
+func TestScanStruct(t *testing.T) {
+	rows := queryFakeRows(t, "single", fakeResultSet{
+		columns: []string{"id", "name", "nickname", "email", "created_by", "unmapped"},
+		values:  [][]driver.Value{{int64(1), "Ann", nil, nil, "admin", "x"}},
+	})
+	defer rows.Close()
+	if !rows.Next() {
+		t.Fatalf("expected a row, got none: %v", rows.Err())
+	}
+	var user scannedUser
+	if err := gqbd.ScanStruct(rows, &user); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := scannedUser{scannedAudit: scannedAudit{CreatedBy: "admin"}, ID: 1, Name: "Ann"}
+	if !reflect.DeepEqual(user, expected) {
+		t.Errorf("expected %+v, got %+v", expected, user)
+	}
+
+	if err := gqbd.ScanStruct(rows, user); err == nil {
+		t.Error("expected error for a non-pointer destination, got nil")
+	}
+}
+
+/*
+ScanStructs
+
+@ Return: Every row scanned into a slice of structs and a slice of struct pointers
+*/
+func TestScanStructs(t *testing.T) {
+	set := fakeResultSet{
+		columns: []string{"id", "name", "nickname", "email"},
+		values: [][]driver.Value{
+			{int64(1), "Ann", "annie", "ann@example.com"},
+			{int64(2), "Bob", nil, nil},
+		},
+	}
+	nickname := "annie"
+	expected := []scannedUser{
+		{ID: 1, Name: "Ann", Nickname: &nickname, Email: sql.NullString{String: "ann@example.com", Valid: true}},
+		{ID: 2, Name: "Bob"},
+	}
+
+	var users []scannedUser
+	if err := gqbd.ScanStructs(queryFakeRows(t, "many", set), &users); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(users, expected) {
+		t.Errorf("expected %+v, got %+v", expected, users)
+	}
+
+	var pointers []*scannedUser
+	if err := gqbd.ScanStructs(queryFakeRows(t, "many", set), &pointers); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(pointers) != 2 || !reflect.DeepEqual(*pointers[1], expected[1]) {
+		t.Errorf("expected %+v, got %+v", expected, pointers)
+	}
+
+	var wrong []int
+	if err := gqbd.ScanStructs(queryFakeRows(t, "many", set), &wrong); err == nil {
+		t.Error("expected error for a slice of non-structs, got nil")
+	}
+}
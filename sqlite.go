@@ -1,110 +1,31 @@
 package gqbd
 
 import (
-	"fmt"
-	"sort"
 	"strings"
 )
 
-func (qb *QueryBuilder) buildSQLiteSelect() (string, []interface{}, error) {
-	var queryBuilder strings.Builder
-	queryBuilder.WriteString("SELECT ")
-	if qb.distinct {
-		queryBuilder.WriteString("DISTINCT ")
-	}
-	queryBuilder.WriteString(strings.Join(qb.columns, ", "))
-	queryBuilder.WriteString(" FROM ")
-	queryBuilder.WriteString(qb.table)
-	if len(qb.joins) > 0 {
-		queryBuilder.WriteString(" " + strings.Join(qb.joins, " "))
-	}
-	if len(qb.conditions) > 0 {
-		queryBuilder.WriteString(" WHERE " + strings.Join(qb.conditions, " AND "))
-	}
-	if len(qb.groupBy) > 0 {
-		queryBuilder.WriteString(" GROUP BY " + strings.Join(qb.groupBy, ", "))
-	}
-	if len(qb.having) > 0 {
-		queryBuilder.WriteString(" HAVING " + strings.Join(qb.having, " AND "))
-	}
-	if qb.orderBy != "" {
-		queryBuilder.WriteString(" ORDER BY " + qb.orderBy)
-	}
-	if qb.limit > 0 {
-		queryBuilder.WriteString(" LIMIT ?")
-		qb.args = append(qb.args, qb.limit)
-	}
-	if qb.offset > 0 {
-		queryBuilder.WriteString(" OFFSET ?")
-		qb.args = append(qb.args, qb.offset)
-	}
-	return queryBuilder.String(), qb.args, nil
-}
-
-func (qb *QueryBuilder) buildSQLiteInsert() (string, []interface{}, error) {
-	if qb.data == nil {
-		return "", nil, fmt.Errorf("no data provided for INSERT")
-	}
-	var cols []string
-	var placeholders []string
-	var args []interface{}
-
-	for col, val := range qb.data {
-		safeCol, err := EscapeIdentifier(qb.dbType, col)
-		if err != nil {
-			return "", nil, err
-		}
-		cols = append(cols, safeCol)
-		placeholders = append(placeholders, "?")
-		args = append(args, val)
-	}
-
-	placeholdersStr := strings.Join(placeholders, ", ")
-	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", qb.table, strings.Join(cols, ", "), placeholdersStr)
-
-	// SQLite supports RETURNING clause (since version 3.35.0)
-	if qb.returning != "" {
-		query += " RETURNING " + qb.returning
-	}
+// sqliteDialect renders SQLite: double-quoted identifiers, "?" placeholders and
+// RETURNING (available since SQLite 3.35.0).
+type sqliteDialect struct{}
 
-	return query, args, nil
+func (sqliteDialect) EscapeIdentifier(name string) (string, error) {
+	return escapeSQLiteIdentifier(name)
 }
 
-func (qb *QueryBuilder) buildSQLiteUpdate() (string, []interface{}, error) {
-	if qb.data == nil {
-		return "", nil, fmt.Errorf("no data provided for UPDATE")
-	}
-	var setClauses []string
-	var updateArgs []interface{}
-
-	var keys []string
-	for key := range qb.data {
-		keys = append(keys, key)
-	}
-	
-	sort.Strings(keys)
-	for _, key := range keys {
-		safeCol, err := EscapeIdentifier(qb.dbType, key)
-		if err != nil {
-			return "", nil, err
-		}
-		setClauses = append(setClauses, fmt.Sprintf("%s = ?", safeCol))
-		updateArgs = append(updateArgs, qb.data[key])
-	}
-
-	setClausesStr := strings.Join(setClauses, ", ")
-	query := fmt.Sprintf("UPDATE %s SET %s", qb.table, setClausesStr)
+func (sqliteDialect) Placeholder(index int) string {
+	return "?"
+}
 
-	allArgs := updateArgs
-	if len(qb.conditions) > 0 {
-		query += " WHERE " + strings.Join(qb.conditions, " AND ")
-		allArgs = append(allArgs, qb.args...)
-	}
+func (sqliteDialect) LimitOffset(limit, offset, nextIndex int, ordered bool) (string, []interface{}, error) {
+	// SQLite also needs a LIMIT before OFFSET; a negative limit means no limit
+	return positionalLimitOffset(limit, offset, "-1")
+}
 
-	return query, allArgs, nil
+func (sqliteDialect) SupportsReturning() bool {
+	return true
 }
 
 func escapeSQLiteIdentifier(name string) (string, error) {
 	// SQLite uses double quotes for identifiers (like PostgreSQL)
-	return `"` + name + `"`, nil
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`, nil
 }
\ No newline at end of file
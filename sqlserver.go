@@ -0,0 +1,70 @@
+package gqbd
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// sqlServerDialect renders T-SQL: bracketed identifiers, @pN placeholders and
+// OFFSET ... FETCH pagination.
+type sqlServerDialect struct{}
+
+func (sqlServerDialect) EscapeIdentifier(name string) (string, error) {
+	return escapeSQLServerIdentifier(name)
+}
+
+func (sqlServerDialect) Placeholder(index int) string {
+	return fmt.Sprintf("@p%d", index)
+}
+
+func (d sqlServerDialect) LimitOffset(limit, offset, nextIndex int, ordered bool) (string, []interface{}, error) {
+	if limit < 0 && offset < 0 {
+		return "", nil, nil
+	}
+	if !ordered {
+		return "", nil, fmt.Errorf("SQL Server pagination requires an ORDER BY clause")
+	}
+	if limit == 0 {
+		// FETCH NEXT must be greater than zero in T-SQL
+		return "", nil, newError(ErrWrongOperation, "SQL Server does not support Limit(0); FETCH NEXT requires a positive row count")
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	// T-SQL paginates with OFFSET ... ROWS FETCH NEXT ... ROWS ONLY, and FETCH needs an OFFSET
+	clause := fmt.Sprintf(" OFFSET %s ROWS", d.Placeholder(nextIndex))
+	args := []interface{}{offset}
+	if limit >= 0 {
+		clause += fmt.Sprintf(" FETCH NEXT %s ROWS ONLY", d.Placeholder(nextIndex+1))
+		args = append(args, limit)
+	}
+	return clause, args, nil
+}
+
+func (sqlServerDialect) SupportsReturning() bool {
+	return false
+}
+
+func escapeSQLServerIdentifier(name string) (string, error) {
+	return "[" + strings.ReplaceAll(name, "]", "]]") + "]", nil
+}
+
+func buildSQLServerConnectionString(config DBConfig) string {
+	dsn := url.URL{Scheme: "sqlserver"}
+	if config.User != "" {
+		if config.Password != "" {
+			dsn.User = url.UserPassword(config.User, config.Password)
+		} else {
+			dsn.User = url.User(config.User)
+		}
+	}
+	dsn.Host = config.Host
+	if config.Port > 0 {
+		dsn.Host = fmt.Sprintf("%s:%d", config.Host, config.Port)
+	}
+	if config.DBName != "" {
+		dsn.RawQuery = url.Values{"database": {config.DBName}}.Encode()
+	}
+	return dsn.String()
+}
@@ -0,0 +1,96 @@
+package gqbd
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+/*
+ValuesStruct
+
+@ v: Struct (or pointer to struct) whose `db:"column"` tagged fields become INSERT values
+@ Return: *QueryBuilder with data set for INSERT
+*/
+func (qb *QueryBuilder) ValuesStruct(v interface{}) *QueryBuilder {
+	if qb.err != nil {
+		return qb
+	}
+	data, err := structToData(v)
+	if err != nil {
+		qb.err = err
+		return qb
+	}
+	return qb.Values(data)
+}
+
+/*
+SetStruct
+
+@ v: Struct (or pointer to struct) whose `db:"column"` tagged fields become UPDATE values
+@ Return: *QueryBuilder with data set for UPDATE
+*/
+func (qb *QueryBuilder) SetStruct(v interface{}) *QueryBuilder {
+	if qb.err != nil {
+		return qb
+	}
+	data, err := structToData(v)
+	if err != nil {
+		qb.err = err
+		return qb
+	}
+	return qb.Set(data)
+}
+
+// structToData maps tagged struct fields to column values.
+// Untagged and `db:"-"` fields are skipped, `db:"col,omitempty"` skips zero values,
+// and untagged embedded structs are flattened into the parent.
+func structToData(v interface{}) (map[string]interface{}, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil, fmt.Errorf("nil struct pointer provided")
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("expected a struct, got %T", v)
+	}
+	data := make(map[string]interface{})
+	collectStructFields(rv, data)
+	return data, nil
+}
+
+func collectStructFields(rv reflect.Value, data map[string]interface{}) {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		tag, hasTag := field.Tag.Lookup("db")
+		if field.Anonymous && !hasTag && field.Type.Kind() == reflect.Struct {
+			collectStructFields(rv.Field(i), data)
+			continue
+		}
+		if field.PkgPath != "" || !hasTag || tag == "-" {
+			continue
+		}
+		name, options := parseStructTag(tag)
+		if name == "" {
+			continue
+		}
+		value := rv.Field(i)
+		if options["omitempty"] && value.IsZero() {
+			continue
+		}
+		data[name] = value.Interface()
+	}
+}
+
+// parseStructTag splits a `db` tag into the column name and its options.
+func parseStructTag(tag string) (string, map[string]bool) {
+	parts := strings.Split(tag, ",")
+	options := make(map[string]bool, len(parts)-1)
+	for _, option := range parts[1:] {
+		options[strings.TrimSpace(option)] = true
+	}
+	return strings.TrimSpace(parts[0]), options
+}